@@ -3,8 +3,11 @@ package auction
 import (
 	"github.com/nspcc-dev/neo-go/pkg/interop"
 	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/iterator"
 	"github.com/nspcc-dev/neo-go/pkg/interop/lib/address"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/gas"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/management"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
 	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
 	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
 )
@@ -13,16 +16,108 @@ import (
 const (
 	initBetKey         = "i"
 	currentBetKey      = "c"
-	lotKey             = "l" // nft id
+	lotKey             = "l" // serialized list of nft ids
 	organizerKey       = "o" // organizer of the auction
 	potentialWinnerKey = "w" // owner of the last bet
+	leaderMaxKey       = "h" // maximum of the potential winner (proxy maximum or the bet), kept in escrow in paid mode
+	paidKey            = "p" // bets are paid and kept by the contract until finish
+	paymentTokenKey    = "f" // NEP-17 contract bets and bonds are paid in, GAS if it's not set
+	maxBetKey          = "m" // resale price cap for the current lot (per unit in multi-unit mode)
+	multiUnitKey       = "u" // tickets of the lot are sold as identical units, see StartMultiUnit
+	bidSeqKey          = "q" // number of unit bids made, orders bids by time
+	unitBidPrefix      = "b" // bidder -> unit bid
+	raffleKey          = "r" // end of raffle registration, see StartRaffle
+	raffleCountKey     = "k" // number of raffle entries
+	raffleEntryPrefix  = "e" // entrant -> true
+	allowlistKey       = "y" // only allowlisted addresses can bet
+	allowedPrefix      = "z" // bidder -> true, allowlist of the current auction
+	bondKey            = "g" // bond in payment token required before the first bet
+	bondPrefix         = "d" // bidder -> deposited bond
+	gateKey            = "t" // only holders of tokens of this NEP-11 contract can bet
+	gateCollectionKey  = "v" // only holders of tokens of this collection of gate contract can bet
+	attrNameKey        = "j" // FrostfsID attribute required from bidders
+	attrValueKey       = "s" // required value of the FrostfsID attribute
+	feeKey             = "E" // platform fee of the current auction in basis points
+	startTimeKey       = "S" // start of the scheduled auction, milliseconds; bets are rejected before it
+	metadataKey        = "M" // serialized AuctionMetadata
+	windowKey          = "D" // settlement window of the current auction, milliseconds
+	nftContractKey     = "C" // NEP-11 contract of the lot if it isn't nft.auc
+
+	adminKey            = "a"
+	priceCapKey         = "x" // max resale price in percent of the ticket face value, 0 - no cap
+	incrementKey        = "n" // step of automatic raise of proxy bids
+	platformFeeKey      = "F" // platform fee in basis points for new auctions, 0 - no fee
+	treasuryKey         = "T" // recipient of platform fees
+	revenueKey          = "R" // payment token -> total platform fees paid to the treasury
+	settlementWindowKey = "G" // settlement window for new paid auctions, milliseconds, 0 - settle on finish
+	settlementSeqKey    = "Q" // number of settlements opened, ID of the last one
+	settlementPrefix    = "P" // settlement ID -> serialized Settlement
+	lastSalePrefix      = "L" // ticket -> price of its last sale in GAS, archive for refunds
+	refundPoolPrefix    = "V" // cancelled collection -> GAS available for refunds
+	refundedPrefix      = "X" // ticket -> refunded amount, refund is claimed once
+	supportedPrefix     = "A" // NEP-11 contract -> true, contracts whose tokens can be auctioned besides nft.auc
+	paymentTokenPrefix  = "K" // NEP-17 contract -> true, tokens auctions can be paid in besides GAS
+
+	maxTitleLength       = 64
+	maxDescriptionLength = 512
+	maxCategoryLength    = 32
+	maxReasonLength      = 256
 
 	nnsSelfDomain         = "auc.auc"
 	nnsNftDomain          = "nft.auc"
+	nnsFrostfsIDDomain    = "frostfsid.frostfs"
 	nnsRecordType         = 16
 	nnsContractHashString = "NcCZaxnLkXvrd56DgpFSSBjhj2DqzH3jKP"
+	domainOwnerAddress    = "NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP" // owner of auc.auc domain
 )
 
+// AuctionMetadata describes the auction for users and search, see Start.
+type AuctionMetadata struct {
+	Title       string
+	Description string
+	Category    string
+}
+
+// Settlement is the result of the paid auction kept by the contract during the
+// settlement window: the lot and the price are released by Claim after Deadline
+// unless the winner disputes it, then the admin resolves the dispute.
+type Settlement struct {
+	ID        int
+	Organizer interop.Hash160
+	Winner    interop.Hash160
+	Lot       [][]byte
+	Price     int
+	Token     interop.Hash160 // NEP-17 contract the price is paid in
+	Fee       int             // platform fee in basis points
+	Deadline  int             // end of the dispute window, milliseconds
+	Disputed  bool
+	Reason    string
+	Nft       interop.Hash160 // NEP-11 contract of the lot
+}
+
+// RoyaltyRecipient is an item of NEP-24 royaltyInfo result of nft contract.
+type RoyaltyRecipient struct {
+	Address interop.Hash160
+	Amount  int
+}
+
+// RaffleInfo is the state of the current raffle returned by RaffleStatus.
+type RaffleInfo struct {
+	Tickets    int
+	Entries    int
+	EntryPrice int
+	End        int // end of registration, milliseconds; 0 if raffle isn't running
+}
+
+// UnitBid is a bid of multi-unit auction: quantity of units wanted at the price
+// per unit. Seq is the order of the bid, earlier bid wins the tie.
+type UnitBid struct {
+	Bidder   interop.Hash160
+	Quantity int
+	Price    int
+	Seq      int
+}
+
 type AuctionItem struct {
 	Owner      interop.Hash160
 	InitialBet int
@@ -31,12 +126,19 @@ type AuctionItem struct {
 }
 
 func _deploy(data interface{}, isUpdate bool) {
+	ctx := storage.GetContext()
 	if isUpdate {
+		// contracts deployed before the admin was introduced get it on update
+		if storage.Get(ctx, adminKey) == nil {
+			storage.Put(ctx, adminKey, deployAdmin(data))
+		}
 		return
 	}
 
+	storage.Put(ctx, adminKey, deployAdmin(data))
+
 	selfHash := runtime.GetExecutingScriptHash()
-	contract.Call(address.ToHash160(nnsContractHashString), "register", contract.All, nnsSelfDomain, address.ToHash160("NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP"), "owner_email@mail.ru", 100, 100, 31536000, 31536000)
+	contract.Call(address.ToHash160(nnsContractHashString), "register", contract.All, nnsSelfDomain, address.ToHash160(domainOwnerAddress), "owner_email@mail.ru", 100, 100, 31536000, 31536000)
 	currentNnsRecord := contract.Call(address.ToHash160(nnsContractHashString), "getRecords", contract.All, nnsSelfDomain, nnsRecordType)
 	if currentNnsRecord != nil {
 		contract.Call(address.ToHash160(nnsContractHashString), "deleteRecords", contract.All, nnsSelfDomain, nnsRecordType)
@@ -45,11 +147,177 @@ func _deploy(data interface{}, isUpdate bool) {
 
 }
 
+// deployAdmin returns the admin passed in deploy (update) data, it's required.
+func deployAdmin(data any) interop.Hash160 {
+	if data == nil {
+		panic("admin is not set in deploy data")
+	}
+	args := data.(struct {
+		Admin interop.Hash160
+	})
+	if len(args.Admin) != 20 {
+		panic("invalid admin hash length")
+	}
+	return args.Admin
+}
+
+// Update updates the contract, only admin can call it. Contracts deployed before
+// the admin was introduced are updated by the owner of their NNS domain.
 func Update(script []byte, manifest []byte, data any) {
+	admin := storage.Get(storage.GetReadOnlyContext(), adminKey)
+	if admin == nil {
+		admin = address.ToHash160(domainOwnerAddress)
+	}
+	if !runtime.CheckWitness(admin.(interop.Hash160)) {
+		panic("not witnessed by admin")
+	}
 	management.UpdateWithData(script, manifest, data)
 }
 
-func Start(auctionOwner interop.Hash160, lotId []byte, initBet int) {
+// Start starts the auction for the lot, a list of tickets (e.g. a pair of seats)
+// sold together. All of them must be owned by the organizer. If paid is true, bets
+// are transferred to the contract in paymentToken (GAS if it's nil, other NEP-17
+// tokens must be added by admin with AddPaymentToken), the previous bet is returned
+// when it's outbid and the final price is paid to the organizer (minus royalty) on
+// finish or, if the settlement window is set (see SetSettlementWindow), by Claim after it.
+// If allowlist is not empty, only its addresses can bet (the organizer can change
+// it with AddToAllowlist and RemoveFromAllowlist). If bond is not 0, bidders must
+// deposit it in paymentToken with DepositBond before the first bet, bonds are returned on finish
+// (if paid is false, the winner's bond is paid to the organizer).
+// If gate is not nil, only holders of its NEP-11 tokens (of gateCollection if it's
+// not 0, gate must implement balanceOfCollection then, like nft) can bet. If
+// attrName is not empty, bidders must have FrostfsID attribute with this name
+// and attrValue (e.g. kyc=passed), FrostfsID is resolved in NNS as frostfsid.frostfs
+// (see also RequireAttribute).
+// If startTime (milliseconds) is in the future, the auction is scheduled and bets
+// are accepted from startTime. The lot of paid, bonded or scheduled auction is
+// transferred to the contract at once, so the organizer can't move it away and
+// block the return of bets and bonds.
+// Title, description and category (a tag like "concert") are returned by
+// GetMetadata, they're limited to 64, 512 and 32 bytes. nftContract is NEP-11
+// contract of the lot, nil for nft.auc tickets; other contracts must be added by
+// admin with AddSupportedContract, their lot is always transferred to the contract
+// at start, as NEP-11 has no approve.
+func Start(auctionOwner interop.Hash160, lot [][]byte, initBet int, paid bool, allowlist []interop.Hash160, bond int,
+	gate interop.Hash160, gateCollection int, attrName string, attrValue string, paymentToken interop.Hash160, startTime int,
+	title string, description string, category string, nftContract interop.Hash160) {
+	if bond < 0 {
+		panic("bond must not be negative")
+	}
+	if gate != nil && len(gate) != 20 {
+		panic("invalid gate contract hash")
+	}
+	if gateCollection < 0 || gateCollection > 0 && gate == nil {
+		panic("invalid gate collection")
+	}
+	if attrName != "" && attrValue == "" {
+		panic("required attribute value is empty")
+	}
+	// the token is called with bidders' witnesses, so only tokens trusted by admin are accepted
+	if paymentToken != nil && (len(paymentToken) != 20 || !IsPaymentToken(paymentToken)) {
+		panic("payment token is not supported")
+	}
+	if len(title) > maxTitleLength {
+		panic("title is longer than " + intToStr(maxTitleLength) + " bytes")
+	}
+	if len(description) > maxDescriptionLength {
+		panic("description is longer than " + intToStr(maxDescriptionLength) + " bytes")
+	}
+	if len(category) > maxCategoryLength {
+		panic("category is longer than " + intToStr(maxCategoryLength) + " bytes")
+	}
+	nftHash := resolveNft()
+	tickets := nftContract == nil || nftContract.Equals(nftHash)
+	if !tickets {
+		if !IsSupported(nftContract) {
+			panic("NFT contract is not supported")
+		}
+		nftHash = nftContract
+	}
+	scheduled := startTime > runtime.GetTime()
+	// lots of other contracts can't be approved to auction, so they're escrowed too
+	start(auctionOwner, nftHash, lot, initBet, paid, false, paid || bond > 0 || scheduled || !tickets)
+
+	ctx := storage.GetContext()
+	if len(allowlist) > 0 {
+		storage.Put(ctx, allowlistKey, true)
+		for _, bidder := range allowlist {
+			storage.Put(ctx, append([]byte(allowedPrefix), bidder...), true)
+		}
+	}
+	if bond > 0 {
+		storage.Put(ctx, bondKey, bond)
+	}
+	if gate != nil {
+		storage.Put(ctx, gateKey, gate)
+		if gateCollection > 0 {
+			storage.Put(ctx, gateCollectionKey, gateCollection)
+		}
+	}
+	if attrName != "" {
+		resolveFrostfsID() // requirement can't be checked without FrostfsID
+		storage.Put(ctx, attrNameKey, attrName)
+		storage.Put(ctx, attrValueKey, attrValue)
+	}
+	if paymentToken != nil && !paymentToken.Equals(gas.Hash) {
+		storage.Put(ctx, paymentTokenKey, paymentToken)
+	}
+	if title != "" || description != "" || category != "" {
+		storage.Put(ctx, metadataKey, std.Serialize(AuctionMetadata{
+			Title:       title,
+			Description: description,
+			Category:    category,
+		}))
+	}
+
+	if scheduled {
+		storage.Put(ctx, startTimeKey, startTime)
+	}
+
+	if scheduled {
+		runtime.Notify("AuctionScheduled", auctionOwner, lot, initBet, startTime)
+		runtime.Notify("info", []byte("New auction"+quoted(title)+" is scheduled for "+intToStr(len(lot))+" ticket(s) with initial bet = "+intToStr(initBet)+" by user "+address.FromHash160(auctionOwner)+", it starts at "+intToStr(startTime)))
+		return
+	}
+
+	runtime.Notify("info", []byte("New auction"+quoted(title)+" started for "+intToStr(len(lot))+" ticket(s) with initial bet = "+intToStr(initBet)+" by user "+address.FromHash160(auctionOwner)))
+}
+
+// StartMultiUnit starts multi-unit auction for identical tickets (e.g. general
+// admission) of the lot. Bidders bid for a quantity of units at a price per unit
+// with BidUnits, reservePrice is the minimum price. On finish the top bids get
+// the units (price desc, earlier bid first on tie, the last winning bid may be
+// filled partially) and all winners pay the clearing price, the lowest price of
+// the winning bids. Unsold tickets stay with the organizer. The lot of paid
+// auction is transferred to the contract at start.
+func StartMultiUnit(auctionOwner interop.Hash160, lot [][]byte, reservePrice int, paid bool) {
+	start(auctionOwner, resolveNft(), lot, reservePrice, paid, true, paid)
+	storage.Put(storage.GetContext(), multiUnitKey, true)
+
+	runtime.Notify("info", []byte("New multi-unit auction started for "+intToStr(len(lot))+" ticket(s) with reserve price = "+intToStr(reservePrice)+" by user "+address.FromHash160(auctionOwner)))
+}
+
+// StartRaffle starts the raffle for the tickets of the lot. Users register with
+// EnterRaffle during duration milliseconds, paying entryPrice in GAS if it's not 0,
+// one entry per address. On finish winners are picked with runtime.GetRandom, each
+// gets one ticket, entries of the others are returned. Unsold tickets stay with the organizer.
+// The lot of paid raffle is transferred to the contract at start.
+func StartRaffle(auctionOwner interop.Hash160, lot [][]byte, entryPrice int, duration int) {
+	if duration <= 0 {
+		panic("duration must be positive")
+	}
+	start(auctionOwner, resolveNft(), lot, entryPrice, entryPrice > 0, true, entryPrice > 0)
+
+	end := runtime.GetTime() + duration
+	storage.Put(storage.GetContext(), raffleKey, end)
+
+	runtime.Notify("info", []byte("New raffle started for "+intToStr(len(lot))+" ticket(s) with entry price = "+intToStr(entryPrice)+" by user "+address.FromHash160(auctionOwner)))
+}
+
+// start checks the lot of nftHash contract, approves nft.auc tickets to the contract
+// (transfers the lot to it if escrow is true) and stores the auction state. If
+// perUnit is true, resale price cap is applied to the price of one ticket.
+func start(auctionOwner interop.Hash160, nftHash interop.Hash160, lot [][]byte, initBet int, paid bool, perUnit bool, escrow bool) {
 	ctx := storage.GetContext()
 
 	currentOwner := storage.Get(ctx, organizerKey)
@@ -59,45 +327,486 @@ func Start(auctionOwner interop.Hash160, lotId []byte, initBet int) {
 	if initBet < 0 {
 		panic("initial bet must not be negative")
 	}
+	if len(lot) == 0 {
+		panic("lot is empty")
+	}
+
+	// tokens of other contracts have no face value, void flag and approve
+	tickets := nftHash.Equals(resolveNft())
+
+	faceValue := 0
+	if !tickets {
+		faceValue = -1
+	}
+	for i, lotId := range lot {
+		for j := 0; j < i; j++ {
+			if string(lot[j]) == string(lotId) {
+				panic("lot contains duplicate ticket " + string(lotId))
+			}
+		}
+
+		ownerOfLot := contract.Call(nftHash, "ownerOf", contract.ReadOnly, lotId).(interop.Hash160)
+		if !ownerOfLot.Equals(auctionOwner) {
+			panic("you can't start auction with ticket " + string(lotId) + " because you're not its owner")
+		}
+		if !tickets {
+			continue
+		}
+		if contract.Call(nftHash, "isVoid", contract.ReadOnly, lotId).(bool) {
+			panic("ticket " + string(lotId) + " is void, the event is cancelled")
+		}
+		// auction transfers the lot to the winner itself, so the lot is approved to it here;
+		// the organizer's witness is scoped to auction and nft contracts, not Global
+		if !escrow {
+			approved := contract.Call(nftHash, "approve", contract.All, runtime.GetExecutingScriptHash(), lotId).(bool)
+			if !approved {
+				panic("failed to approve the lot to auction")
+			}
+		}
+
+		// cap of the lot is the sum of caps of its tickets, it can't be applied if any
+		// ticket has no face value
+		if faceValue >= 0 {
+			ticketFaceValue := contract.Call(nftHash, "getFaceValue", contract.ReadOnly, lotId).(int)
+			if ticketFaceValue > 0 {
+				faceValue += ticketFaceValue
+			} else {
+				faceValue = -1
+			}
+		}
+	}
 
-	nftContractHashStringArray := contract.Call(address.ToHash160(nnsContractHashString), "resolve", contract.All, nnsNftDomain, nnsRecordType).([]string)
-	nftContractHashString := nftContractHashStringArray[0]
-	ownerOfLot := contract.Call(address.ToHash160(nftContractHashString), "ownerOf", contract.All, lotId).(interop.Hash160)
-	if !ownerOfLot.Equals(auctionOwner) {
-		panic("you can't start auction with this lot because you're not its owner")
+	priceCap := storage.Get(ctx, priceCapKey)
+	if priceCap != nil && faceValue > 0 {
+		if perUnit {
+			faceValue = faceValue / len(lot)
+		}
+		maxBet := faceValue * priceCap.(int) / 100
+		if initBet > maxBet {
+			panic("initial bet exceeds resale price cap " + intToStr(maxBet))
+		}
+		storage.Put(ctx, maxBetKey, maxBet)
+	}
+	fee := storage.Get(ctx, platformFeeKey)
+	if fee != nil && paid {
+		storage.Put(ctx, feeKey, fee.(int))
+	}
+	window := storage.Get(ctx, settlementWindowKey)
+	if window != nil && paid && !perUnit {
+		storage.Put(ctx, windowKey, window.(int))
 	}
 
+	if !tickets {
+		storage.Put(ctx, nftContractKey, nftHash)
+	}
 	storage.Put(ctx, organizerKey, auctionOwner)
-	storage.Put(ctx, lotKey, lotId)
+	storage.Put(ctx, lotKey, std.Serialize(lot))
 	storage.Put(ctx, initBetKey, initBet)
 	storage.Put(ctx, currentBetKey, initBet)
+	if paid {
+		storage.Put(ctx, paidKey, true)
+	}
+
+	// OnNEP11Payment accepts the lot only after the auction state is stored
+	if escrow {
+		for _, lotID := range lot {
+			transferred := contract.Call(nftHash, "transfer", contract.All, runtime.GetExecutingScriptHash(), lotID, nil).(bool)
+			if !transferred {
+				panic("failed to transfer token " + string(lotID) + " to auction")
+			}
+		}
+	}
+}
+
+// AddToAllowlist allows the bidders to bet in the current auction with allowlist.
+// Only organizer can call it.
+func AddToAllowlist(organizer interop.Hash160, bidders []interop.Hash160) {
+	ctx := checkAllowlistOrganizer(organizer)
+	for _, bidder := range bidders {
+		storage.Put(ctx, append([]byte(allowedPrefix), bidder...), true)
+	}
+	runtime.Notify("info", []byte(intToStr(len(bidders))+" address(es) are added to the allowlist"))
+}
+
+// RemoveFromAllowlist forbids the bidders to make new bets in the current auction
+// with allowlist, bets already made stay. Only organizer can call it.
+func RemoveFromAllowlist(organizer interop.Hash160, bidders []interop.Hash160) {
+	ctx := checkAllowlistOrganizer(organizer)
+	for _, bidder := range bidders {
+		storage.Delete(ctx, append([]byte(allowedPrefix), bidder...))
+	}
+	runtime.Notify("info", []byte(intToStr(len(bidders))+" address(es) are removed from the allowlist"))
+}
+
+// RequireAttribute sets the FrostfsID attribute required from bidders of the
+// current auction, empty attrName removes the requirement. It's the way to require
+// an attribute in multi-unit auctions and raffles, they're started without it.
+// Bets already made stay. Only organizer can call it.
+func RequireAttribute(organizer interop.Hash160, attrName string, attrValue string) {
+	ctx := storage.GetContext()
+	auctionOwner := storage.Get(ctx, organizerKey)
+	if auctionOwner == nil {
+		panic("auction has not started")
+	}
+	if !organizer.Equals(auctionOwner.(interop.Hash160)) || !runtime.CheckWitness(organizer) {
+		panic("only organizer can change the required attribute")
+	}
+
+	if attrName == "" {
+		storage.Delete(ctx, attrNameKey)
+		storage.Delete(ctx, attrValueKey)
+		runtime.Notify("info", []byte("FrostfsID attribute is not required anymore"))
+		return
+	}
+	if attrValue == "" {
+		panic("required attribute value is empty")
+	}
+	resolveFrostfsID() // requirement can't be checked without FrostfsID
+	storage.Put(ctx, attrNameKey, attrName)
+	storage.Put(ctx, attrValueKey, attrValue)
+
+	runtime.Notify("info", []byte("Bidders are required to have FrostfsID attribute "+attrName+"="+attrValue))
+}
+
+func checkAllowlistOrganizer(organizer interop.Hash160) storage.Context {
+	ctx := storage.GetContext()
+	auctionOwner := storage.Get(ctx, organizerKey)
+	if auctionOwner == nil {
+		panic("auction has not started")
+	}
+	if !organizer.Equals(auctionOwner.(interop.Hash160)) || !runtime.CheckWitness(organizer) {
+		panic("only organizer can change the allowlist")
+	}
+	if storage.Get(ctx, allowlistKey) == nil {
+		panic("current auction has no allowlist")
+	}
+	return ctx
+}
+
+// DepositBond transfers the bond required by the current auction from the bidder
+// to the contract.
+func DepositBond(bidder interop.Hash160) {
+	ctx := storage.GetContext()
+
+	bond := storage.Get(ctx, bondKey)
+	if bond == nil {
+		panic("current auction doesn't require a bond")
+	}
+	key := append([]byte(bondPrefix), bidder...)
+	if storage.Get(ctx, key) != nil {
+		panic("bond is already deposited")
+	}
+
+	if !transfer(ctx, bidder, runtime.GetExecutingScriptHash(), bond.(int)) {
+		panic("failed to transfer bond")
+	}
+	storage.Put(ctx, key, bond.(int))
+
+	runtime.Notify("info", []byte("User "+address.FromHash160(bidder)+" deposited the bond"))
+}
+
+// checkBidder panics if the bidder isn't allowed to bet in the current auction:
+// isn't allowlisted, hasn't deposited the bond, doesn't hold the gating token or
+// doesn't have the required FrostfsID attribute. Bets on void lots are rejected too.
+func checkBidder(ctx storage.Context, bidder interop.Hash160) {
+	startTime := storage.Get(ctx, startTimeKey)
+	if startTime != nil && runtime.GetTime() < startTime.(int) {
+		panic("auction hasn't started yet, it starts at " + intToStr(startTime.(int)))
+	}
+	if lotIsVoid(getNftContract(ctx), std.Deserialize(storage.Get(ctx, lotKey).([]byte)).([][]byte)) {
+		panic("lot is void, the event is cancelled; the auction can only be finished")
+	}
+	if storage.Get(ctx, allowlistKey) != nil && storage.Get(ctx, append([]byte(allowedPrefix), bidder...)) == nil {
+		panic("you're not in the allowlist of this auction")
+	}
+	if storage.Get(ctx, bondKey) != nil && storage.Get(ctx, append([]byte(bondPrefix), bidder...)) == nil {
+		panic("deposit the bond before the first bet")
+	}
+
+	gate := storage.Get(ctx, gateKey)
+	if gate != nil {
+		var balance int
+		gateCollection := storage.Get(ctx, gateCollectionKey)
+		if gateCollection != nil {
+			balance = contract.Call(gate.(interop.Hash160), "balanceOfCollection", contract.ReadOnly, bidder, gateCollection.(int)).(int)
+		} else {
+			balance = contract.Call(gate.(interop.Hash160), "balanceOf", contract.ReadOnly, bidder).(int)
+		}
+		if balance <= 0 {
+			panic("only holders of the gating token can bet in this auction")
+		}
+	}
+
+	attrName := storage.Get(ctx, attrNameKey)
+	if attrName != nil {
+		value := contract.Call(resolveFrostfsID(), "getSubjectKV", contract.ReadOnly, bidder, attrName.(string)).(string)
+		if value != storage.Get(ctx, attrValueKey).(string) {
+			panic("bidder doesn't have required FrostfsID attribute " + attrName.(string))
+		}
+	}
+}
+
+// resolveFrostfsID returns FrostfsID contract hash from its NNS TXT records, the
+// one that is an address (like nns contract takes it).
+func resolveFrostfsID() interop.Hash160 {
+	records := contract.Call(address.ToHash160(nnsContractHashString), "resolve", contract.ReadOnly, nnsFrostfsIDDomain, nnsRecordType).([]string)
+	for _, record := range records {
+		if len(record) == 34 {
+			return address.ToHash160(record)
+		}
+	}
+	panic("FrostfsID is not found in NNS")
+}
+
+// returnBonds returns deposited bonds to the bidders, except the bond of keep
+// (e.g. defaulting winner), which is paid to the organizer.
+func returnBonds(ctx storage.Context, organizer interop.Hash160, keep interop.Hash160) {
+	self := runtime.GetExecutingScriptHash()
+	it := storage.Find(ctx, bondPrefix, storage.KeysOnly|storage.RemovePrefix)
+	for iterator.Next(it) {
+		bidder := iterator.Value(it).(interop.Hash160)
+		key := append([]byte(bondPrefix), bidder...)
+		to := bidder
+		if keep != nil && bidder.Equals(keep) {
+			to = organizer
+		}
+		if !transfer(ctx, self, to, storage.Get(ctx, key).(int)) {
+			panic("failed to return bond")
+		}
+		storage.Delete(ctx, key)
+	}
+}
+
+// EnterRaffle registers the entrant in the current raffle, entry price is transferred
+// to the contract in GAS.
+func EnterRaffle(entrant interop.Hash160) {
+	ctx := storage.GetContext()
+
+	end := storage.Get(ctx, raffleKey)
+	if end == nil {
+		panic("raffle is not running")
+	}
+	if runtime.GetTime() > end.(int) {
+		panic("raffle registration is closed")
+	}
+	if entrant.Equals(storage.Get(ctx, organizerKey).(interop.Hash160)) {
+		panic("raffle organizer cannot enter")
+	}
+	checkBidder(ctx, entrant)
+
+	key := append([]byte(raffleEntryPrefix), entrant...)
+	if storage.Get(ctx, key) != nil {
+		panic("you have already entered the raffle")
+	}
+
+	entryPrice := storage.Get(ctx, initBetKey).(int)
+	if entryPrice > 0 && !transfer(ctx, entrant, runtime.GetExecutingScriptHash(), entryPrice) {
+		panic("failed to transfer entry price")
+	}
+
+	storage.Put(ctx, key, true)
+	count := 0
+	countData := storage.Get(ctx, raffleCountKey)
+	if countData != nil {
+		count = countData.(int)
+	}
+	storage.Put(ctx, raffleCountKey, count+1)
+
+	runtime.Notify("info", []byte("User "+address.FromHash160(entrant)+" entered the raffle"))
+}
+
+// BidUnits makes the bid of multi-unit auction for quantity units at the price per unit.
+// The new bid of the same bidder replaces the previous one and loses its time priority.
+// In paid mode quantity * price is transferred to the contract, the previous bid is returned.
+func BidUnits(bidder interop.Hash160, quantity int, price int) {
+	ctx := storage.GetContext()
+
+	auctionOwner := storage.Get(ctx, organizerKey)
+	if auctionOwner == nil {
+		panic("auction has not started")
+	}
+	if storage.Get(ctx, multiUnitKey) == nil {
+		panic("current auction is not multi-unit")
+	}
+	if bidder.Equals(auctionOwner.(interop.Hash160)) {
+		panic("auction owner cannot make bet")
+	}
+	checkBidder(ctx, bidder)
+
+	units := len(std.Deserialize(storage.Get(ctx, lotKey).([]byte)).([][]byte))
+	if quantity <= 0 || quantity > units {
+		panic("quantity must be from 1 to " + intToStr(units))
+	}
+	if price < storage.Get(ctx, initBetKey).(int) || price <= 0 {
+		panic("price is lower than the reserve price")
+	}
+	maxBet := storage.Get(ctx, maxBetKey)
+	if maxBet != nil && price > maxBet.(int) {
+		panic("price exceeds resale price cap " + intToStr(maxBet.(int)))
+	}
+
+	key := append([]byte(unitBidPrefix), bidder...)
+	if storage.Get(ctx, paidKey) != nil {
+		self := runtime.GetExecutingScriptHash()
+		if !transfer(ctx, bidder, self, quantity*price) {
+			panic("failed to transfer bet")
+		}
+
+		previous := storage.Get(ctx, key)
+		if previous != nil {
+			prevBid := std.Deserialize(previous.([]byte)).(UnitBid)
+			if !transfer(ctx, self, bidder, prevBid.Quantity*prevBid.Price) {
+				panic("failed to return previous bet")
+			}
+		}
+	}
+
+	seq := 0
+	seqData := storage.Get(ctx, bidSeqKey)
+	if seqData != nil {
+		seq = seqData.(int)
+	}
+	storage.Put(ctx, bidSeqKey, seq+1)
+
+	storage.Put(ctx, key, std.Serialize(UnitBid{
+		Bidder:   bidder,
+		Quantity: quantity,
+		Price:    price,
+		Seq:      seq,
+	}))
 
-	runtime.Notify("info", []byte("New auction started with initial bet = "+intToStr(initBet)+" by user "+address.FromHash160(auctionOwner)))
+	runtime.Notify("info", []byte("New bet for "+intToStr(quantity)+" unit(s) at "+intToStr(price)+" is made by user "+address.FromHash160(bidder)))
 }
 
+// MakeBet makes the bet. If the potential winner has a proxy maximum higher
+// than the bet, the bet only raises the visible bet, see MakeProxyBid.
 func MakeBet(better interop.Hash160, bet int) {
+	placeBet(better, bet, false)
+}
+
+// MakeProxyBid submits the maximum the bidder is ready to pay. The visible bet
+// is kept at the minimum needed to lead and is raised by the bid increment when
+// someone else bids, up to the maximum. Notifications show visible bets only,
+// but the maximum isn't secret: it's the argument of the call and, in paid mode,
+// the amount of the transfer to the contract. In paid mode the maximum is kept
+// by the contract, the rest of it is returned to the winner on finish.
+func MakeProxyBid(bidder interop.Hash160, maxBid int) {
+	placeBet(bidder, maxBid, true)
+}
+
+// placeBet makes the bet (proxy is false) or the proxy bid with maximum amount.
+func placeBet(better interop.Hash160, amount int, proxy bool) {
 	ctx := storage.GetContext()
 
 	auctionOwner := storage.Get(ctx, organizerKey).(interop.Hash160)
 	if auctionOwner == nil {
 		panic("auction has not started")
 	}
+	if storage.Get(ctx, multiUnitKey) != nil {
+		panic("current auction is multi-unit, use bidUnits")
+	}
+	if storage.Get(ctx, raffleKey) != nil {
+		panic("current auction is raffle, use enterRaffle")
+	}
 	if better.Equals(auctionOwner) {
 		panic("auction owner cannot make bet")
 	}
+	checkBidder(ctx, better)
 
 	currentBet := storage.Get(ctx, currentBetKey).(int)
-	if bet <= currentBet {
+	if amount <= currentBet {
 		panic("bet must be higher than the current bet")
 	}
+	maxBet := storage.Get(ctx, maxBetKey)
+	if maxBet != nil && amount > maxBet.(int) {
+		panic("bet exceeds resale price cap " + intToStr(maxBet.(int)))
+	}
+
+	increment := GetBidIncrement()
+	paid := storage.Get(ctx, paidKey) != nil
+	self := runtime.GetExecutingScriptHash()
+
+	leaderData := storage.Get(ctx, potentialWinnerKey)
+	leaderMax := getLeaderMax(ctx)
+
+	// the potential winner raises own maximum (or bets over it), the visible bet
+	// is raised only by a simple bet
+	if leaderData != nil && better.Equals(leaderData.(interop.Hash160)) {
+		if amount <= leaderMax {
+			if proxy {
+				panic("maximum must be higher than the current one")
+			}
+			storage.Put(ctx, currentBetKey, amount)
+			runtime.Notify("info", []byte("New bet = "+intToStr(amount)+" is made by user "+address.FromHash160(better)))
+			return
+		}
+		if paid && !transfer(ctx, better, self, amount-leaderMax) {
+			panic("failed to transfer bet")
+		}
+		storage.Put(ctx, leaderMaxKey, amount)
+		if !proxy {
+			storage.Put(ctx, currentBetKey, amount)
+			runtime.Notify("info", []byte("New bet = "+intToStr(amount)+" is made by user "+address.FromHash160(better)))
+		}
+		return
+	}
+
+	// the potential winner's maximum is enough to keep the lead (earlier bid wins
+	// the tie), its visible bet is raised automatically
+	if leaderData != nil && amount <= leaderMax {
+		visible := amount + increment
+		if visible > leaderMax {
+			visible = leaderMax
+		}
+		storage.Put(ctx, currentBetKey, visible)
+		if !proxy {
+			runtime.Notify("info", []byte("New bet = "+intToStr(amount)+" is made by user "+address.FromHash160(better)))
+		}
+		runtime.Notify("info", []byte("Bet is raised to "+intToStr(visible)+" for user "+address.FromHash160(leaderData.(interop.Hash160))))
+		return
+	}
+
+	visible := amount
+	if proxy {
+		// minimum needed to lead
+		visible = currentBet + increment
+		if leaderData != nil && leaderMax+increment > visible {
+			visible = leaderMax + increment
+		}
+		if visible > amount {
+			visible = amount
+		}
+	}
+
+	if paid {
+		if !transfer(ctx, better, self, amount) {
+			panic("failed to transfer bet")
+		}
+		if leaderData != nil && !transfer(ctx, self, leaderData.(interop.Hash160), leaderMax) {
+			panic("failed to return previous bet")
+		}
+	}
 
-	storage.Put(ctx, currentBetKey, bet)
+	storage.Put(ctx, currentBetKey, visible)
 	storage.Put(ctx, potentialWinnerKey, better)
+	storage.Put(ctx, leaderMaxKey, amount)
 
-	runtime.Notify("info", []byte("New bet = "+intToStr(bet)+" is made by user "+address.FromHash160(better)))
+	runtime.Notify("info", []byte("New bet = "+intToStr(visible)+" is made by user "+address.FromHash160(better)))
+}
 
+// getLeaderMax returns the maximum of the potential winner.
+func getLeaderMax(ctx storage.Context) int {
+	data := storage.Get(ctx, leaderMaxKey)
+	if data == nil {
+		return storage.Get(ctx, currentBetKey).(int)
+	}
+	return data.(int)
 }
 
+// Finish finishes the current auction, only the organizer can call it. If the
+// lot contains void tickets (the event is cancelled), anyone can finish the
+// auction, then it's terminated: all bets and bonds are refunded and the lot is
+// returned to the organizer.
 func Finish(finishInitiator interop.Hash160) interop.Hash160 {
 	ctx := storage.GetReadOnlyContext()
 
@@ -105,13 +814,35 @@ func Finish(finishInitiator interop.Hash160) interop.Hash160 {
 	if lotData == nil {
 		panic("LotID is not set in storage; auction isn't started")
 	}
-	lotID := lotData.([]byte)
+	lot := std.Deserialize(lotData.([]byte)).([][]byte)
 
 	ownerOfLot := storage.Get(ctx, organizerKey).(interop.Hash160)
+	if lotIsVoid(getNftContract(ctx), lot) {
+		message := terminate(ownerOfLot, lot)
+		returnBonds(storage.GetContext(), ownerOfLot, nil)
+		clearStorage()
+		runtime.Notify("info", []byte(message))
+		return ownerOfLot
+	}
 	if !ownerOfLot.Equals(finishInitiator) {
 		panic("you can't finish  with lot because you're not its owner")
 	}
 
+	if storage.Get(ctx, multiUnitKey) != nil {
+		winner, message := finishMultiUnit(ownerOfLot, lot)
+		returnBonds(storage.GetContext(), ownerOfLot, nil)
+		clearStorage()
+		runtime.Notify("info", []byte(message))
+		return winner
+	}
+	if storage.Get(ctx, raffleKey) != nil {
+		winner, message := finishRaffle(ownerOfLot, lot)
+		returnBonds(storage.GetContext(), ownerOfLot, nil)
+		clearStorage()
+		runtime.Notify("info", []byte(message))
+		return winner
+	}
+
 	var winner interop.Hash160
 	winnerData := storage.Get(ctx, potentialWinnerKey)
 	if winnerData == nil {
@@ -120,55 +851,1146 @@ func Finish(finishInitiator interop.Hash160) interop.Hash160 {
 		winner = winnerData.(interop.Hash160)
 	}
 
-	nftContractHashStringArray := contract.Call(address.ToHash160(nnsContractHashString), "resolve", contract.All, nnsNftDomain, nnsRecordType).([]string)
-	nftContractHashString := nftContractHashStringArray[0]
-	contract.Call(address.ToHash160(nftContractHashString), "transfer", contract.All, winner, lotID, nil)
+	paid := storage.Get(ctx, paidKey) != nil && winnerData != nil
+	window := storage.Get(ctx, windowKey)
+	escrow := paid && window != nil
+
+	nftHash := getNftContract(ctx)
+	self := runtime.GetExecutingScriptHash()
+	for _, lotID := range lot {
+		// during the settlement window the lot is kept by the contract
+		to := winner
+		if escrow {
+			to = self
+			owner := contract.Call(nftHash, "ownerOf", contract.ReadOnly, lotID).(interop.Hash160)
+			if owner.Equals(self) {
+				continue // the lot is escrowed at start
+			}
+		}
+		transferred := contract.Call(nftHash, "transfer", contract.All, to, lotID, nil).(bool)
+		if !transferred {
+			panic("failed to transfer ticket " + string(lotID) + ", approval for auction has been revoked")
+		}
+	}
+
+	message := "Auction has been finished. Winner is: " + address.FromHash160(winner)
+	if paid {
+		price := storage.Get(ctx, currentBetKey).(int)
+		// unused part of the proxy maximum is returned to the winner
+		rest := getLeaderMax(ctx) - price
+		if rest > 0 && !transfer(ctx, self, winner, rest) {
+			panic("failed to return the rest of the bet")
+		}
+		if escrow {
+			id := openSettlement(Settlement{
+				Organizer: ownerOfLot,
+				Winner:    winner,
+				Lot:       lot,
+				Price:     price,
+				Token:     getPaymentToken(ctx),
+				Fee:       getAuctionFee(ctx),
+				Deadline:  runtime.GetTime() + window.(int),
+				Nft:       nftHash,
+			})
+			message += ". Price: " + intToStr(price) + ", the lot and the price are kept until settlement " + intToStr(id) + ", the winner can dispute it before " + intToStr(runtime.GetTime()+window.(int))
+		} else {
+			royalty := payRoyalties(nftHash, getPaymentToken(ctx), lot, winner, price)
+			fee := payPlatformFee(getPaymentToken(ctx), getAuctionFee(ctx), price, price-royalty)
+			if !transfer(ctx, self, ownerOfLot, price-royalty-fee) {
+				panic("failed to pay the organizer")
+			}
+			message += ". Price: " + intToStr(price) + ", royalty: " + intToStr(royalty) + ", platform fee: " + intToStr(fee) + ", organizer gets: " + intToStr(price-royalty-fee)
+		}
+	}
 
+	// without paid bets the winner pays the organizer outside of the contract, the
+	// winner's bond is paid to the organizer then, so defaulting costs the winner
+	// the bond; in paid mode the price is already paid and all bonds are returned
+	var keep interop.Hash160
+	if !paid && winnerData != nil {
+		keep = winner
+	}
+	returnBonds(storage.GetContext(), ownerOfLot, keep)
 	clearStorage()
 
-	runtime.Notify("info", []byte("Auction has been finished. Winner is: "+address.FromHash160(winner)))
+	runtime.Notify("info", []byte(message))
 
 	return winner
 }
 
-func ShowCurrentBet() string {
-	data := storage.Get(storage.GetReadOnlyContext(), currentBetKey)
-	if data == nil {
-		return "0"
+// finishMultiUnit allocates units of the lot to the top bids and settles payments.
+// It returns the top bidder (organizer if there are no bids) and the finish message.
+func finishMultiUnit(organizer interop.Hash160, lot [][]byte) (interop.Hash160, string) {
+	ctx := storage.GetContext()
+
+	bids := []UnitBid{}
+	it := storage.Find(ctx, unitBidPrefix, storage.ValuesOnly|storage.DeserializeValues)
+	for iterator.Next(it) {
+		bid := iterator.Value(it).(UnitBid)
+		// insertion sort: higher price first, earlier bid first on the same price
+		i := len(bids)
+		bids = append(bids, bid)
+		for i > 0 && (bids[i-1].Price < bid.Price || bids[i-1].Price == bid.Price && bids[i-1].Seq > bid.Seq) {
+			bids[i] = bids[i-1]
+			i--
+		}
+		bids[i] = bid
 	}
-	return string(data.([]byte))
+
+	remaining := len(lot)
+	clearingPrice := 0
+	allocated := []int{}
+	for _, bid := range bids {
+		units := 0
+		if remaining > 0 {
+			units = bid.Quantity
+			if units > remaining {
+				units = remaining // partial fill of the last winning bid
+			}
+			remaining -= units
+			clearingPrice = bid.Price
+		}
+		allocated = append(allocated, units)
+	}
+
+	nftHash := getNftContract(ctx)
+	paid := storage.Get(ctx, paidKey) != nil
+	self := runtime.GetExecutingScriptHash()
+
+	next := 0
+	total := 0
+	royalty := 0
+	for i, bid := range bids {
+		units := allocated[i]
+		won := [][]byte{}
+		for k := 0; k < units; k++ {
+			won = append(won, lot[next+k])
+			transferred := contract.Call(nftHash, "transfer", contract.All, bid.Bidder, lot[next+k], nil).(bool)
+			if !transferred {
+				panic("failed to transfer ticket " + string(lot[next+k]) + ", approval for auction has been revoked")
+			}
+		}
+
+		if paid {
+			price := units * clearingPrice
+			if units > 0 {
+				royalty += payRoyalties(nftHash, getPaymentToken(ctx), won, bid.Bidder, price)
+			}
+			total += price
+			refund := bid.Quantity*bid.Price - price
+			if refund > 0 && !transfer(ctx, self, bid.Bidder, refund) {
+				panic("failed to return bet")
+			}
+		}
+		next += units
+
+		storage.Delete(ctx, append([]byte(unitBidPrefix), bid.Bidder...))
+	}
+
+	// unsold tickets are "transferred" to the organizer, it resets their approval for auction
+	for ; next < len(lot); next++ {
+		transferred := contract.Call(nftHash, "transfer", contract.All, organizer, lot[next], nil).(bool)
+		if !transferred {
+			panic("failed to return ticket " + string(lot[next]) + ", approval for auction has been revoked")
+		}
+	}
+
+	message := "Multi-unit auction has been finished. Units sold: " + intToStr(len(lot)-remaining) + ", clearing price: " + intToStr(clearingPrice)
+	if paid && total > 0 {
+		fee := payPlatformFee(getPaymentToken(ctx), getAuctionFee(ctx), total, total-royalty)
+		if !transfer(ctx, self, organizer, total-royalty-fee) {
+			panic("failed to pay the organizer")
+		}
+		message += ". Price: " + intToStr(total) + ", royalty: " + intToStr(royalty) + ", platform fee: " + intToStr(fee) + ", organizer gets: " + intToStr(total-royalty-fee)
+	}
+
+	if len(bids) == 0 {
+		return organizer, message
+	}
+	return bids[0].Bidder, message
 }
 
-func ShowLotId() string {
-	data := storage.Get(storage.GetReadOnlyContext(), lotKey)
-	if data == nil {
-		return "nil"
+// finishRaffle picks random winners of the raffle, one ticket each, and settles
+// entry payments. It returns the first winner (organizer if there are no entries)
+// and the finish message.
+func finishRaffle(organizer interop.Hash160, lot [][]byte) (interop.Hash160, string) {
+	ctx := storage.GetContext()
+
+	if runtime.GetTime() <= storage.Get(ctx, raffleKey).(int) {
+		panic("raffle registration is not closed yet")
 	}
 
-	return string(data.([]byte))
+	entrants := []interop.Hash160{}
+	it := storage.Find(ctx, raffleEntryPrefix, storage.KeysOnly|storage.RemovePrefix)
+	for iterator.Next(it) {
+		entrants = append(entrants, iterator.Value(it).(interop.Hash160))
+	}
+
+	winners := len(lot)
+	if len(entrants) < winners {
+		winners = len(entrants)
+	}
+
+	nftHash := getNftContract(ctx)
+
+	// partial Fisher-Yates shuffle: the first winners entrants are picked at random
+	for i := 0; i < winners; i++ {
+		j := i + runtime.GetRandom()%(len(entrants)-i)
+		winner := entrants[j]
+		entrants[j] = entrants[i]
+		entrants[i] = winner
+
+		transferred := contract.Call(nftHash, "transfer", contract.All, winner, lot[i], nil).(bool)
+		if !transferred {
+			panic("failed to transfer ticket " + string(lot[i]) + ", approval for auction has been revoked")
+		}
+	}
+
+	// unsold tickets are "transferred" to the organizer, it resets their approval for auction
+	for i := winners; i < len(lot); i++ {
+		transferred := contract.Call(nftHash, "transfer", contract.All, organizer, lot[i], nil).(bool)
+		if !transferred {
+			panic("failed to return ticket " + string(lot[i]) + ", approval for auction has been revoked")
+		}
+	}
+
+	paid := storage.Get(ctx, paidKey) != nil
+	entryPrice := storage.Get(ctx, initBetKey).(int)
+	self := runtime.GetExecutingScriptHash()
+	royalty := 0
+	for i, entrant := range entrants {
+		storage.Delete(ctx, append([]byte(raffleEntryPrefix), entrant...))
+		if !paid {
+			continue
+		}
+		if i < winners {
+			royalty += payRoyalties(nftHash, getPaymentToken(ctx), [][]byte{lot[i]}, entrant, entryPrice)
+		} else if !transfer(ctx, self, entrant, entryPrice) {
+			panic("failed to return entry price")
+		}
+	}
+
+	message := "Raffle has been finished. Entries: " + intToStr(len(entrants)) + ", tickets given: " + intToStr(winners)
+	if paid && winners > 0 {
+		total := winners * entryPrice
+		fee := payPlatformFee(getPaymentToken(ctx), getAuctionFee(ctx), total, total-royalty)
+		if !transfer(ctx, self, organizer, total-royalty-fee) {
+			panic("failed to pay the organizer")
+		}
+		message += ". Price: " + intToStr(total) + ", royalty: " + intToStr(royalty) + ", platform fee: " + intToStr(fee) + ", organizer gets: " + intToStr(total-royalty-fee)
+	}
+
+	if winners == 0 {
+		return organizer, message
+	}
+	return entrants[0], message
 }
 
-func intToStr(value int) string {
-	if value == 0 {
-		return "0"
+// terminate refunds bets, unit bids or raffle entries of the current auction
+// with the void lot and returns the lot to the organizer. It returns the finish message.
+func terminate(organizer interop.Hash160, lot [][]byte) string {
+	ctx := storage.GetContext()
+
+	paid := storage.Get(ctx, paidKey) != nil
+	self := runtime.GetExecutingScriptHash()
+	refunded := 0
+
+	it := storage.Find(ctx, unitBidPrefix, storage.ValuesOnly|storage.DeserializeValues)
+	for iterator.Next(it) {
+		bid := iterator.Value(it).(UnitBid)
+		if paid && !transfer(ctx, self, bid.Bidder, bid.Quantity*bid.Price) {
+			panic("failed to return bet")
+		}
+		storage.Delete(ctx, append([]byte(unitBidPrefix), bid.Bidder...))
+		refunded++
 	}
-	var chars = "0123456789"
-	var result string
-	for value > 0 {
-		result = string(chars[value%10]) + result
-		value = value / 10
+
+	entryPrice := storage.Get(ctx, initBetKey).(int)
+	it = storage.Find(ctx, raffleEntryPrefix, storage.KeysOnly|storage.RemovePrefix)
+	for iterator.Next(it) {
+		entrant := iterator.Value(it).(interop.Hash160)
+		if paid && !transfer(ctx, self, entrant, entryPrice) {
+			panic("failed to return entry price")
+		}
+		storage.Delete(ctx, append([]byte(raffleEntryPrefix), entrant...))
+		refunded++
 	}
 
-	return result
+	leader := storage.Get(ctx, potentialWinnerKey)
+	if leader != nil {
+		if paid && !transfer(ctx, self, leader.(interop.Hash160), getLeaderMax(ctx)) {
+			panic("failed to return bet")
+		}
+		refunded++
+	}
+
+	// escrowed tickets are returned, approved ones are "transferred" to the
+	// organizer to reset their approval for auction
+	nftHash := getNftContract(ctx)
+	for _, lotID := range lot {
+		transferred := contract.Call(nftHash, "transfer", contract.All, organizer, lotID, nil).(bool)
+		if !transferred {
+			panic("failed to return ticket " + string(lotID))
+		}
+	}
+
+	return "Auction has been terminated, the event is cancelled. Bets refunded: " + intToStr(refunded)
 }
 
-// clearStorage in this moment this func delete all values storage by hardcode prefix
-func clearStorage() {
+// lotIsVoid returns true if any ticket of the lot belongs to a cancelled collection.
+// Only nft.auc tickets can be void.
+func lotIsVoid(nftHash interop.Hash160, lot [][]byte) bool {
+	if !nftHash.Equals(resolveNft()) {
+		return false
+	}
+	for _, lotID := range lot {
+		if contract.Call(nftHash, "isVoid", contract.ReadOnly, lotID).(bool) {
+			return true
+		}
+	}
+	return false
+}
+
+// openSettlement stores the settlement with the next ID and returns the ID.
+func openSettlement(st Settlement) int {
 	ctx := storage.GetContext()
 
-	storage.Delete(ctx, initBetKey)
-	storage.Delete(ctx, currentBetKey)
-	storage.Delete(ctx, potentialWinnerKey)
-	storage.Delete(ctx, lotKey)
-	storage.Delete(ctx, organizerKey)
+	id := 1
+	data := storage.Get(ctx, settlementSeqKey)
+	if data != nil {
+		id = data.(int) + 1
+	}
+	storage.Put(ctx, settlementSeqKey, id)
+
+	st.ID = id
+	storage.Put(ctx, settlementKey(id), std.Serialize(st))
+	return id
+}
+
+// Dispute flags a problem with the lot of the settlement (e.g. invalid FrostFS
+// metadata hash or redeemed ticket). Only the winner can dispute it, before the
+// deadline; the lot and the price are kept until the admin resolves the dispute.
+func Dispute(winner interop.Hash160, id int, reason string) {
+	ctx := storage.GetContext()
+
+	st := getSettlement(ctx, id)
+	if !st.Winner.Equals(winner) || !runtime.CheckWitness(winner) {
+		panic("only the winner can dispute the settlement")
+	}
+	if st.Disputed {
+		panic("settlement is already disputed")
+	}
+	if runtime.GetTime() > st.Deadline {
+		panic("dispute window is closed")
+	}
+	if len(reason) > maxReasonLength {
+		panic("reason is longer than " + intToStr(maxReasonLength) + " bytes")
+	}
+
+	st.Disputed = true
+	st.Reason = reason
+	storage.Put(ctx, settlementKey(id), std.Serialize(st))
+
+	runtime.Notify("info", []byte("Settlement "+intToStr(id)+" is disputed by the winner: "+reason))
+}
+
+// Resolve resolves the disputed settlement. If refund is true, the price is
+// returned to the winner and the lot to the organizer, otherwise the settlement
+// is released like with Claim. Only admin (the arbiter) can call it.
+func Resolve(id int, refund bool) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	st := getSettlement(ctx, id)
+	if !st.Disputed {
+		panic("settlement is not disputed")
+	}
+	if !refund {
+		releaseSettlement(ctx, st)
+		return
+	}
+	refundSettlement(ctx, st, "by the arbiter")
+}
+
+// Claim completes the settlement after the dispute window if it isn't disputed:
+// the lot goes to the winner and the price (minus royalty and platform fee) to
+// the organizer. If the lot is void (the event is cancelled), the settlement is
+// refunded at once. Anyone can call it.
+func Claim(id int) {
+	ctx := storage.GetContext()
+
+	st := getSettlement(ctx, id)
+	if lotIsVoid(st.Nft, st.Lot) {
+		refundSettlement(ctx, st, "as the event is cancelled")
+		return
+	}
+	if st.Disputed {
+		panic("settlement is disputed, wait for the arbiter")
+	}
+	if runtime.GetTime() <= st.Deadline {
+		panic("dispute window is not closed yet")
+	}
+	releaseSettlement(ctx, st)
+}
+
+// releaseSettlement transfers the lot to the winner and pays the organizer,
+// royalty and platform fee.
+func releaseSettlement(ctx storage.Context, st Settlement) {
+	self := runtime.GetExecutingScriptHash()
+	for _, lotID := range st.Lot {
+		transferred := contract.Call(st.Nft, "transfer", contract.All, st.Winner, lotID, nil).(bool)
+		if !transferred {
+			panic("failed to transfer ticket " + string(lotID))
+		}
+	}
+
+	royalty := payRoyalties(st.Nft, st.Token, st.Lot, st.Winner, st.Price)
+	fee := payPlatformFee(st.Token, st.Fee, st.Price, st.Price-royalty)
+	if !transferToken(st.Token, self, st.Organizer, st.Price-royalty-fee) {
+		panic("failed to pay the organizer")
+	}
+	storage.Delete(ctx, settlementKey(st.ID))
+
+	runtime.Notify("info", []byte("Settlement "+intToStr(st.ID)+" is completed. Winner is: "+address.FromHash160(st.Winner)+
+		". Price: "+intToStr(st.Price)+", royalty: "+intToStr(royalty)+", platform fee: "+intToStr(fee)+", organizer gets: "+intToStr(st.Price-royalty-fee)))
+}
+
+// refundSettlement returns the price to the winner and the lot to the organizer.
+func refundSettlement(ctx storage.Context, st Settlement, reason string) {
+	self := runtime.GetExecutingScriptHash()
+	for _, lotID := range st.Lot {
+		transferred := contract.Call(st.Nft, "transfer", contract.All, st.Organizer, lotID, nil).(bool)
+		if !transferred {
+			panic("failed to return ticket " + string(lotID))
+		}
+	}
+	if !transferToken(st.Token, self, st.Winner, st.Price) {
+		panic("failed to refund the winner")
+	}
+	storage.Delete(ctx, settlementKey(st.ID))
+
+	runtime.Notify("info", []byte("Settlement "+intToStr(st.ID)+" is refunded "+reason+": "+intToStr(st.Price)+" is returned to "+address.FromHash160(st.Winner)))
+}
+
+func getSettlement(ctx storage.Context, id int) Settlement {
+	data := storage.Get(ctx, settlementKey(id))
+	if data == nil {
+		panic("settlement " + intToStr(id) + " is not found")
+	}
+	return std.Deserialize(data.([]byte)).(Settlement)
+}
+
+func settlementKey(id int) []byte {
+	return append([]byte(settlementPrefix), []byte(intToStr(id))...)
+}
+
+// OnNEP17Payment accepts bets and bonds of the current auction in its payment token.
+// GAS with cancelled collection ID in data funds the refund pool of the collection,
+// see FundRefundPool.
+func OnNEP17Payment(from interop.Hash160, amount int, data any) {
+	if data != nil {
+		if !runtime.GetCallingScriptHash().Equals(gas.Hash) {
+			panic("refund pool is funded in GAS only")
+		}
+		collectionID := data.(int)
+		if !contract.Call(resolveNft(), "isCancelled", contract.ReadOnly, collectionID).(bool) {
+			panic("collection " + intToStr(collectionID) + " is not cancelled")
+		}
+		ctx := storage.GetContext()
+		pool := getRefundPool(ctx, collectionID) + amount
+		storage.Put(ctx, refundPoolKey(collectionID), pool)
+
+		runtime.Notify("info", []byte("User "+address.FromHash160(from)+" added "+intToStr(amount)+" to the refund pool of collection "+intToStr(collectionID)+", pool: "+intToStr(pool)))
+		return
+	}
+
+	ctx := storage.GetReadOnlyContext()
+	if storage.Get(ctx, paidKey) == nil && storage.Get(ctx, bondKey) == nil {
+		panic("current auction doesn't accept payments")
+	}
+	if !runtime.GetCallingScriptHash().Equals(getPaymentToken(ctx)) {
+		panic("only payment token of the current auction is accepted")
+	}
+}
+
+// OnNEP11Payment accepts the lot of the current auction escrowed at start (lot of
+// paid, bonded or scheduled auction or of NEP-11 contract other than nft.auc).
+func OnNEP11Payment(from interop.Hash160, amount int, token []byte, data any) {
+	ctx := storage.GetReadOnlyContext()
+	if !runtime.GetCallingScriptHash().Equals(getNftContract(ctx)) {
+		panic("only tokens of the current auction contract are accepted")
+	}
+	organizer := storage.Get(ctx, organizerKey)
+	if organizer == nil || !from.Equals(organizer.(interop.Hash160)) {
+		panic("only the lot of the current auction is accepted")
+	}
+	lot := std.Deserialize(storage.Get(ctx, lotKey).([]byte)).([][]byte)
+	for _, lotID := range lot {
+		if string(lotID) == string(token) {
+			return
+		}
+	}
+	panic("ticket is not in the lot of the current auction")
+}
+
+// resolveNft returns nft contract hash from NNS.
+func resolveNft() interop.Hash160 {
+	nftContractHashStringArray := contract.Call(address.ToHash160(nnsContractHashString), "resolve", contract.ReadOnly, nnsNftDomain, nnsRecordType).([]string)
+	return address.ToHash160(nftContractHashStringArray[0])
+}
+
+// getNftContract returns NEP-11 contract of the current auction lot, nft.auc by default.
+func getNftContract(ctx storage.Context) interop.Hash160 {
+	nftHash := storage.Get(ctx, nftContractKey)
+	if nftHash == nil {
+		return resolveNft()
+	}
+	return nftHash.(interop.Hash160)
+}
+
+// getPaymentToken returns NEP-17 contract the current auction is paid in.
+func getPaymentToken(ctx storage.Context) interop.Hash160 {
+	token := storage.Get(ctx, paymentTokenKey)
+	if token == nil {
+		return interop.Hash160(gas.Hash)
+	}
+	return token.(interop.Hash160)
+}
+
+// transfer transfers amount of the payment token of the current auction.
+func transfer(ctx storage.Context, from interop.Hash160, to interop.Hash160, amount int) bool {
+	return transferToken(getPaymentToken(ctx), from, to, amount)
+}
+
+// transferToken transfers amount of the NEP-17 token.
+func transferToken(token interop.Hash160, from interop.Hash160, to interop.Hash160, amount int) bool {
+	return contract.Call(token, "transfer", contract.All, from, to, amount, nil).(bool)
+}
+
+// payRoyalties pays NEP-24 royalties for the lot sold at the price from the
+// bets kept by the contract and returns the total paid amount. The price is
+// split between the tickets of the lot equally, the remainder goes to the first one.
+// Ticket prices of sales in GAS are archived as the last sale prices for refunds.
+// Royalties aren't paid if the contract doesn't implement NEP-24.
+func payRoyalties(nftHash interop.Hash160, token interop.Hash160, lot [][]byte, buyer interop.Hash160, price int) int {
+	ctx := storage.GetContext()
+	self := runtime.GetExecutingScriptHash()
+	archive := token.Equals(gas.Hash) && nftHash.Equals(resolveNft())
+	royalties := management.HasMethod(nftHash, "royaltyInfo", 3)
+
+	total := 0
+	for i, lotID := range lot {
+		ticketPrice := price / len(lot)
+		if i == 0 {
+			ticketPrice += price % len(lot)
+		}
+		if archive {
+			storage.Put(ctx, append([]byte(lastSalePrefix), lotID...), ticketPrice)
+		}
+		if !royalties {
+			continue
+		}
+
+		recipients := contract.Call(nftHash, "royaltyInfo", contract.ReadOnly, lotID, token, ticketPrice).([]RoyaltyRecipient)
+		for _, r := range recipients {
+			if r.Amount <= 0 {
+				continue
+			}
+			total += r.Amount
+			if total > price {
+				panic("royalty exceeds the price")
+			}
+			if !transferToken(token, self, r.Address, r.Amount) {
+				panic("failed to pay royalty")
+			}
+			runtime.Notify("RoyaltiesTransferred", token, r.Address, buyer, lotID, r.Amount)
+		}
+	}
+
+	return total
+}
+
+// getAuctionFee returns the platform fee of the current auction in basis points.
+func getAuctionFee(ctx storage.Context) int {
+	bps := storage.Get(ctx, feeKey)
+	if bps == nil {
+		return 0
+	}
+	return bps.(int)
+}
+
+// payPlatformFee pays the platform fee (bps of the price in the token) to the
+// treasury and returns it. The fee doesn't exceed limit, the part of the price left
+// after royalties.
+func payPlatformFee(token interop.Hash160, bps int, price int, limit int) int {
+	ctx := storage.GetContext()
+
+	fee := price * bps / 10000
+	if fee > limit {
+		fee = limit
+	}
+	if fee <= 0 {
+		return 0
+	}
+
+	treasury := storage.Get(ctx, treasuryKey).(interop.Hash160)
+	if !transferToken(token, runtime.GetExecutingScriptHash(), treasury, fee) {
+		panic("failed to pay platform fee")
+	}
+
+	key := append([]byte(revenueKey), token...)
+	revenue := 0
+	data := storage.Get(ctx, key)
+	if data != nil {
+		revenue = data.(int)
+	}
+	storage.Put(ctx, key, revenue+fee)
+
+	return fee
+}
+
+// FundRefundPool transfers amount of GAS from the funder (the event organizer)
+// to the refund pool of the cancelled collection.
+func FundRefundPool(funder interop.Hash160, collectionID int, amount int) {
+	if amount <= 0 {
+		panic("amount must be positive")
+	}
+	if !gas.Transfer(funder, runtime.GetExecutingScriptHash(), amount, collectionID) {
+		panic("failed to transfer GAS to the refund pool")
+	}
+}
+
+// ClaimRefund pays the refund for the void ticket to its holder from the refund
+// pool of the ticket collection. The refund is the last sale price of the ticket
+// in auction archive, tickets that weren't sold for GAS here have no refund. It
+// can be claimed once per ticket.
+func ClaimRefund(holder interop.Hash160, token []byte) {
+	if !runtime.CheckWitness(holder) {
+		panic("not witnessed by holder")
+	}
+	ctx := storage.GetContext()
+
+	nftHash := resolveNft()
+	if !contract.Call(nftHash, "isVoid", contract.ReadOnly, token).(bool) {
+		panic("ticket is not void")
+	}
+	owner := contract.Call(nftHash, "ownerOf", contract.ReadOnly, token).(interop.Hash160)
+	if !owner.Equals(holder) {
+		panic("only the ticket holder can claim the refund")
+	}
+	key := append([]byte(refundedPrefix), token...)
+	if storage.Get(ctx, key) != nil {
+		panic("refund for the ticket is already claimed")
+	}
+
+	amount := getRefundAmount(ctx, token)
+	if amount <= 0 {
+		panic("ticket has no sale price to refund")
+	}
+	collectionID := contract.Call(nftHash, "collectionOf", contract.ReadOnly, token).(int)
+	pool := getRefundPool(ctx, collectionID)
+	if pool < amount {
+		panic("refund pool of collection " + intToStr(collectionID) + " is insufficient: " + intToStr(pool))
+	}
+
+	storage.Put(ctx, refundPoolKey(collectionID), pool-amount)
+	storage.Put(ctx, key, amount)
+	if !gas.Transfer(runtime.GetExecutingScriptHash(), holder, amount, nil) {
+		panic("failed to pay the refund")
+	}
+
+	runtime.Notify("info", []byte("User "+address.FromHash160(holder)+" is refunded "+intToStr(amount)+" for ticket "+string(token)))
+}
+
+// getRefundAmount returns the last sale price of the ticket, 0 if there is no
+// sale in the archive. Face value isn't a fallback: tickets minted before it was
+// stored in GAS fractions keep it in whole GAS.
+func getRefundAmount(ctx storage.Context, token []byte) int {
+	price := storage.Get(ctx, append([]byte(lastSalePrefix), token...))
+	if price == nil {
+		return 0
+	}
+	return price.(int)
+}
+
+func getRefundPool(ctx storage.Context, collectionID int) int {
+	data := storage.Get(ctx, refundPoolKey(collectionID))
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+func refundPoolKey(collectionID int) []byte {
+	return append([]byte(refundPoolPrefix), []byte(intToStr(collectionID))...)
+}
+
+// AddSupportedContract allows auctions of tokens of the NEP-11 contract (e.g. nns
+// domains), nft.auc tickets are always supported. Only admin can call it.
+func AddSupportedContract(nftContract interop.Hash160) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if len(nftContract) != 20 || management.GetContract(nftContract) == nil {
+		panic("invalid NFT contract")
+	}
+	if !management.HasMethod(nftContract, "ownerOf", 1) || !management.HasMethod(nftContract, "transfer", 3) {
+		panic("contract is not a non-divisible NEP-11 token")
+	}
+	storage.Put(ctx, append([]byte(supportedPrefix), nftContract...), true)
+}
+
+// RemoveSupportedContract disallows new auctions of tokens of the NEP-11 contract,
+// the current auction isn't affected. Only admin can call it.
+func RemoveSupportedContract(nftContract interop.Hash160) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	storage.Delete(ctx, append([]byte(supportedPrefix), nftContract...))
+}
+
+// IsSupported returns true if tokens of the NEP-11 contract can be auctioned.
+func IsSupported(nftContract interop.Hash160) bool {
+	if nftContract.Equals(resolveNft()) {
+		return true
+	}
+	return storage.Get(storage.GetReadOnlyContext(), append([]byte(supportedPrefix), nftContract...)) != nil
+}
+
+// SupportedContracts returns iterator over NEP-11 contracts added by AddSupportedContract.
+func SupportedContracts() iterator.Iterator {
+	return storage.Find(storage.GetReadOnlyContext(), supportedPrefix, storage.KeysOnly|storage.RemovePrefix)
+}
+
+// AddPaymentToken allows auctions paid in the NEP-17 token, GAS is always allowed.
+// Bets, bonds and refunds are transferred by the token with bidders' witnesses,
+// so only trusted tokens must be added. Only admin can call it.
+func AddPaymentToken(token interop.Hash160) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if len(token) != 20 || management.GetContract(token) == nil {
+		panic("invalid payment token")
+	}
+	if !management.HasMethod(token, "transfer", 4) || !management.HasMethod(token, "balanceOf", 1) {
+		panic("contract is not a NEP-17 token")
+	}
+	storage.Put(ctx, append([]byte(paymentTokenPrefix), token...), true)
+}
+
+// RemovePaymentToken disallows new auctions paid in the NEP-17 token, the current
+// auction isn't affected. Only admin can call it.
+func RemovePaymentToken(token interop.Hash160) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	storage.Delete(ctx, append([]byte(paymentTokenPrefix), token...))
+}
+
+// IsPaymentToken returns true if auctions can be paid in the NEP-17 token.
+func IsPaymentToken(token interop.Hash160) bool {
+	if token.Equals(gas.Hash) {
+		return true
+	}
+	return storage.Get(storage.GetReadOnlyContext(), append([]byte(paymentTokenPrefix), token...)) != nil
+}
+
+// PaymentTokens returns iterator over NEP-17 tokens added by AddPaymentToken.
+func PaymentTokens() iterator.Iterator {
+	return storage.Find(storage.GetReadOnlyContext(), paymentTokenPrefix, storage.KeysOnly|storage.RemovePrefix)
+}
+
+// SetPriceCap sets the maximum resale price in percent of the ticket face value
+// (e.g. 120 allows 20% margin). It's applied to auctions started after the call,
+// 0 disables the cap. Only admin can call it.
+func SetPriceCap(percent int) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if percent < 0 {
+		panic("price cap must not be negative")
+	}
+	if percent == 0 {
+		storage.Delete(ctx, priceCapKey)
+		return
+	}
+	storage.Put(ctx, priceCapKey, percent)
+}
+
+// SetPlatformFee sets the platform fee in basis points (e.g. 250 is 2.5%) deducted
+// from the price of paid auctions on finish and sent to the treasury. It's applied
+// to auctions started after the call, 0 disables the fee. Only admin can call it.
+func SetPlatformFee(bps int, treasury interop.Hash160) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if bps < 0 || bps > 10000 {
+		panic("platform fee must be from 0 to 10000 basis points")
+	}
+	if bps == 0 {
+		storage.Delete(ctx, platformFeeKey)
+		return
+	}
+	if len(treasury) != 20 {
+		panic("invalid treasury hash length")
+	}
+	storage.Put(ctx, platformFeeKey, bps)
+	storage.Put(ctx, treasuryKey, treasury)
+}
+
+// GetPlatformFee returns the platform fee in basis points and the treasury, nil
+// if there is no fee.
+func GetPlatformFee() []any {
+	ctx := storage.GetReadOnlyContext()
+	bps := storage.Get(ctx, platformFeeKey)
+	if bps == nil {
+		return nil
+	}
+	return []any{bps.(int), storage.Get(ctx, treasuryKey).(interop.Hash160)}
+}
+
+// GetRevenue returns total platform fees paid to the treasury in the NEP-17 token.
+func GetRevenue(token interop.Hash160) int {
+	data := storage.Get(storage.GetReadOnlyContext(), append([]byte(revenueKey), token...))
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// SetBidIncrement sets the step of automatic raise of proxy bids. Only admin can call it.
+func SetBidIncrement(increment int) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if increment <= 0 {
+		panic("bid increment must be positive")
+	}
+	storage.Put(ctx, incrementKey, increment)
+}
+
+// GetBidIncrement returns the step of automatic raise of proxy bids, 1 by default.
+func GetBidIncrement() int {
+	data := storage.Get(storage.GetReadOnlyContext(), incrementKey)
+	if data == nil {
+		return 1
+	}
+	return data.(int)
+}
+
+// GetPriceCap returns the maximum resale price in percent of the face value, 0 if there is no cap.
+func GetPriceCap() int {
+	data := storage.Get(storage.GetReadOnlyContext(), priceCapKey)
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// UnitBids returns an iterator with bids of the current multi-unit auction.
+func UnitBids() iterator.Iterator {
+	return storage.Find(storage.GetReadOnlyContext(), unitBidPrefix, storage.ValuesOnly|storage.DeserializeValues)
+}
+
+// RaffleStatus returns the state of the current raffle.
+func RaffleStatus() RaffleInfo {
+	ctx := storage.GetReadOnlyContext()
+
+	end := storage.Get(ctx, raffleKey)
+	if end == nil {
+		return RaffleInfo{}
+	}
+
+	entries := 0
+	count := storage.Get(ctx, raffleCountKey)
+	if count != nil {
+		entries = count.(int)
+	}
+
+	return RaffleInfo{
+		Tickets:    len(std.Deserialize(storage.Get(ctx, lotKey).([]byte)).([][]byte)),
+		Entries:    entries,
+		EntryPrice: storage.Get(ctx, initBetKey).(int),
+		End:        end.(int),
+	}
+}
+
+// IsEntered returns true if the entrant is registered in the current raffle.
+func IsEntered(entrant interop.Hash160) bool {
+	return storage.Get(storage.GetReadOnlyContext(), append([]byte(raffleEntryPrefix), entrant...)) != nil
+}
+
+// IsAllowed returns true if the bidder can bet in the current auction: there is
+// no allowlist or the bidder is in it.
+func IsAllowed(bidder interop.Hash160) bool {
+	ctx := storage.GetReadOnlyContext()
+	return storage.Get(ctx, allowlistKey) == nil || storage.Get(ctx, append([]byte(allowedPrefix), bidder...)) != nil
+}
+
+// GetGate returns the NEP-11 contract which tokens are required to bet in the
+// current auction and its collection (0 - any token), nil if there is no gating.
+func GetGate() []any {
+	ctx := storage.GetReadOnlyContext()
+	gate := storage.Get(ctx, gateKey)
+	if gate == nil {
+		return nil
+	}
+	gateCollection := storage.Get(ctx, gateCollectionKey)
+	if gateCollection == nil {
+		gateCollection = 0
+	}
+	return []any{gate, gateCollection}
+}
+
+// GetRequiredAttribute returns name and value of FrostfsID attribute required from
+// bidders of the current auction, nil if there is no requirement.
+func GetRequiredAttribute() []string {
+	ctx := storage.GetReadOnlyContext()
+	attrName := storage.Get(ctx, attrNameKey)
+	if attrName == nil {
+		return nil
+	}
+	return []string{attrName.(string), storage.Get(ctx, attrValueKey).(string)}
+}
+
+// quoted returns the title in quotes with a leading space for messages, empty
+// string if there is no title.
+func quoted(title string) string {
+	if title == "" {
+		return ""
+	}
+	return " \"" + title + "\""
+}
+
+// GetLastSalePrice returns the price of the last sale of the ticket in GAS, 0
+// if the ticket wasn't sold for GAS here.
+func GetLastSalePrice(token []byte) int {
+	data := storage.Get(storage.GetReadOnlyContext(), append([]byte(lastSalePrefix), token...))
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// GetRefund returns the refund for the void ticket: the amount the holder can
+// claim and whether it's already claimed (then the amount is the claimed one).
+func GetRefund(token []byte) []any {
+	ctx := storage.GetReadOnlyContext()
+	claimed := storage.Get(ctx, append([]byte(refundedPrefix), token...))
+	if claimed != nil {
+		return []any{claimed.(int), true}
+	}
+	return []any{getRefundAmount(ctx, token), false}
+}
+
+// GetRefundPool returns GAS available for refunds of the cancelled collection.
+func GetRefundPool(collectionID int) int {
+	return getRefundPool(storage.GetReadOnlyContext(), collectionID)
+}
+
+// GetSettlement returns the open settlement.
+func GetSettlement(id int) Settlement {
+	return getSettlement(storage.GetReadOnlyContext(), id)
+}
+
+// Settlements returns iterator over open settlements.
+func Settlements() iterator.Iterator {
+	return storage.Find(storage.GetReadOnlyContext(), settlementPrefix, storage.ValuesOnly|storage.DeserializeValues)
+}
+
+// SetSettlementWindow sets the time in milliseconds after finish of paid auction
+// during which the winner can dispute the result, the lot and the price are kept
+// by the contract until Claim or Resolve. It's applied to auctions started after
+// the call, 0 settles auctions on finish. Only admin can call it.
+func SetSettlementWindow(window int) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	if window < 0 {
+		panic("settlement window must not be negative")
+	}
+	if window == 0 {
+		storage.Delete(ctx, settlementWindowKey)
+		return
+	}
+	storage.Put(ctx, settlementWindowKey, window)
+}
+
+// GetSettlementWindow returns the settlement window for new paid auctions in milliseconds.
+func GetSettlementWindow() int {
+	data := storage.Get(storage.GetReadOnlyContext(), settlementWindowKey)
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// GetOrganizer returns the organizer of the current auction, nil if there is no auction.
+func GetOrganizer() interop.Hash160 {
+	data := storage.Get(storage.GetReadOnlyContext(), organizerKey)
+	if data == nil {
+		return nil
+	}
+	return data.(interop.Hash160)
+}
+
+// GetLimits returns maximum lengths in bytes of auction title, description,
+// category and dispute reason.
+func GetLimits() []int {
+	return []int{maxTitleLength, maxDescriptionLength, maxCategoryLength, maxReasonLength}
+}
+
+// GetMetadata returns title, description and category of the current auction,
+// they're empty if the auction has no metadata.
+func GetMetadata() AuctionMetadata {
+	data := storage.Get(storage.GetReadOnlyContext(), metadataKey)
+	if data == nil {
+		return AuctionMetadata{}
+	}
+	return std.Deserialize(data.([]byte)).(AuctionMetadata)
+}
+
+// GetState returns the state of the auction: "none" if there is no auction,
+// "void" if its lot is void (anyone can finish it to refund bets), "scheduled"
+// if it hasn't started yet (see GetStartTime) or "running".
+func GetState() string {
+	ctx := storage.GetReadOnlyContext()
+	if storage.Get(ctx, organizerKey) == nil {
+		return "none"
+	}
+	if lotIsVoid(getNftContract(ctx), std.Deserialize(storage.Get(ctx, lotKey).([]byte)).([][]byte)) {
+		return "void"
+	}
+	startTime := storage.Get(ctx, startTimeKey)
+	if startTime != nil && runtime.GetTime() < startTime.(int) {
+		return "scheduled"
+	}
+	return "running"
+}
+
+// GetStartTime returns the start of the scheduled auction in milliseconds, 0 if
+// the auction isn't scheduled.
+func GetStartTime() int {
+	data := storage.Get(storage.GetReadOnlyContext(), startTimeKey)
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// GetNftContract returns NEP-11 contract of the current auction lot (nft.auc by default).
+func GetNftContract() interop.Hash160 {
+	return getNftContract(storage.GetReadOnlyContext())
+}
+
+// GetPaymentToken returns NEP-17 contract bets and bonds of the current auction are
+// paid in (GAS by default).
+func GetPaymentToken() interop.Hash160 {
+	return getPaymentToken(storage.GetReadOnlyContext())
+}
+
+// GetBond returns the bond required by the current auction, 0 if there is no bond.
+func GetBond() int {
+	data := storage.Get(storage.GetReadOnlyContext(), bondKey)
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// BondOf returns the bond deposited by the bidder in the current auction.
+func BondOf(bidder interop.Hash160) int {
+	data := storage.Get(storage.GetReadOnlyContext(), append([]byte(bondPrefix), bidder...))
+	if data == nil {
+		return 0
+	}
+	return data.(int)
+}
+
+// IsMultiUnit returns true if the current auction is multi-unit.
+func IsMultiUnit() bool {
+	return storage.Get(storage.GetReadOnlyContext(), multiUnitKey) != nil
+}
+
+// MaxBet returns the maximum bet allowed for the current lot (per unit in multi-unit
+// auction), -1 if bets aren't capped.
+func MaxBet() int {
+	data := storage.Get(storage.GetReadOnlyContext(), maxBetKey)
+	if data == nil {
+		return -1
+	}
+	return data.(int)
+}
+
+func ShowCurrentBet() string {
+	data := storage.Get(storage.GetReadOnlyContext(), currentBetKey)
+	if data == nil {
+		return "0"
+	}
+	return string(data.([]byte))
+}
+
+// ShowLot returns IDs of the tickets of the current lot, nil if auction isn't started.
+func ShowLot() [][]byte {
+	data := storage.Get(storage.GetReadOnlyContext(), lotKey)
+	if data == nil {
+		return nil
+	}
+
+	return std.Deserialize(data.([]byte)).([][]byte)
+}
+
+func checkAdmin(ctx storage.Context) {
+	if !runtime.CheckWitness(storage.Get(ctx, adminKey).(interop.Hash160)) {
+		panic("not witnessed by admin")
+	}
+}
+
+func intToStr(value int) string {
+	if value == 0 {
+		return "0"
+	}
+	var chars = "0123456789"
+	var result string
+	for value > 0 {
+		result = string(chars[value%10]) + result
+		value = value / 10
+	}
+
+	return result
+}
+
+// clearStorage in this moment this func delete all values storage by hardcode prefix
+func clearStorage() {
+	ctx := storage.GetContext()
+
+	storage.Delete(ctx, initBetKey)
+	storage.Delete(ctx, currentBetKey)
+	storage.Delete(ctx, potentialWinnerKey)
+	storage.Delete(ctx, leaderMaxKey)
+	storage.Delete(ctx, lotKey)
+	storage.Delete(ctx, organizerKey)
+	storage.Delete(ctx, paidKey)
+	storage.Delete(ctx, maxBetKey)
+	storage.Delete(ctx, multiUnitKey)
+	storage.Delete(ctx, bidSeqKey)
+	storage.Delete(ctx, raffleKey)
+	storage.Delete(ctx, raffleCountKey)
+	storage.Delete(ctx, allowlistKey)
+	storage.Delete(ctx, bondKey)
+	storage.Delete(ctx, gateKey)
+	storage.Delete(ctx, gateCollectionKey)
+	storage.Delete(ctx, paymentTokenKey)
+	storage.Delete(ctx, feeKey)
+	storage.Delete(ctx, startTimeKey)
+	storage.Delete(ctx, metadataKey)
+	storage.Delete(ctx, windowKey)
+	storage.Delete(ctx, nftContractKey)
+	storage.Delete(ctx, attrNameKey)
+	storage.Delete(ctx, attrValueKey)
+
+	it := storage.Find(ctx, allowedPrefix, storage.KeysOnly)
+	for iterator.Next(it) {
+		storage.Delete(ctx, iterator.Value(it).([]byte))
+	}
 }
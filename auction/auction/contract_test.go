@@ -0,0 +1,602 @@
+package auction_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/cli/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/compiler"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/neotest"
+	"github.com/nspcc-dev/neo-go/pkg/neotest/chain"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/manifest"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/nef"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+// Contracts resolve each other in NNS at the fixed address and register their
+// domains for the fixed owner, tests replace both with the ones of the test chain.
+const (
+	nnsAddress   = "NcCZaxnLkXvrd56DgpFSSBjhj2DqzH3jKP"
+	ownerAddress = "NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP"
+
+	gasUnit = 1_0000_0000
+)
+
+type testEnv struct {
+	e       *neotest.Executor
+	nns     util.Uint160
+	nft     util.Uint160
+	auction util.Uint160
+}
+
+// newTestEnv deploys nns, nft and auction contracts, all of them are owned by the committee.
+func newTestEnv(t *testing.T) *testEnv {
+	bc, validator, committee := chain.NewMulti(t)
+	e := neotest.NewExecutor(t, bc, validator, committee)
+	e.DisableCoverage()
+
+	// committee deploys contracts and owns NNS domains, it pays for them
+	e.ValidatorInvoker(e.NativeHash(t, nativenames.Gas)).Invoke(t, true, "transfer",
+		e.Validator.ScriptHash(), e.CommitteeHash, 10000*gasUnit, nil)
+
+	env := &testEnv{e: e, nns: deployNNS(t, e)}
+	env.nft = env.deploy(t, "../nft", []any{e.CommitteeHash})
+	env.auction = env.deploy(t, ".", []any{e.CommitteeHash})
+	return env
+}
+
+// deployNNS deploys compiled nns contract, its sources depend on frostfs-contract module.
+func deployNNS(t *testing.T, e *neotest.Executor) util.Uint160 {
+	nefData, err := os.ReadFile("../nns/contract.nef")
+	require.NoError(t, err)
+	ne, err := nef.FileFromBytes(nefData)
+	require.NoError(t, err)
+
+	manifestData, err := os.ReadFile("../nns/contract.manifest.json")
+	require.NoError(t, err)
+	m := new(manifest.Manifest)
+	require.NoError(t, json.Unmarshal(manifestData, m))
+
+	c := &neotest.Contract{
+		Hash:     state.CreateContractHash(e.CommitteeHash, ne.Checksum, m.Name),
+		NEF:      &ne,
+		Manifest: m,
+	}
+	e.DeployContractBy(t, e.Committee, c, nil)
+	return c.Hash
+}
+
+// deploy compiles the contract from dir with NNS address and domain owner of the
+// test chain and deploys it by the committee.
+func (env *testEnv) deploy(t *testing.T, dir string, data any) util.Uint160 {
+	src, err := os.ReadFile(filepath.Join(dir, "contract.go"))
+	require.NoError(t, err)
+	patched := strings.ReplaceAll(string(src), nnsAddress, address.Uint160ToString(env.nns))
+	patched = strings.ReplaceAll(patched, ownerAddress, address.Uint160ToString(env.e.CommitteeHash))
+
+	conf, err := smartcontract.ParseContractConfig(filepath.Join(dir, "contract.yml"))
+	require.NoError(t, err)
+	opts := &compiler.Options{
+		Name:                       conf.Name,
+		SourceURL:                  conf.SourceURL,
+		ContractEvents:             conf.Events,
+		DeclaredNamedTypes:         conf.NamedTypes,
+		ContractSupportedStandards: conf.SupportedStandards,
+		SafeMethods:                conf.SafeMethods,
+		Overloads:                  conf.Overloads,
+		Permissions:                make([]manifest.Permission, len(conf.Permissions)),
+	}
+	for i := range conf.Permissions {
+		opts.Permissions[i] = manifest.Permission(conf.Permissions[i])
+	}
+
+	// the source is compiled as contract.go of this package, so it's built with
+	// the interop version of this module
+	c := neotest.CompileSource(t, env.e.CommitteeHash, strings.NewReader(patched), opts)
+	env.e.DeployContractBy(t, env.e.Committee, c, data)
+	return c.Hash
+}
+
+// mintTickets creates a collection of n tickets and mints all of them to the owner.
+func (env *testEnv) mintTickets(t *testing.T, owner util.Uint160, n int) []any {
+	nftInv := env.e.CommitteeInvoker(env.nft)
+	nftInv.Invoke(t, 1, "createCollection", "Concert", "", "", n)
+
+	lot := make([]any, 0, n)
+	for serial := 1; serial <= n; serial++ {
+		nftInv.Invoke(t, stackitem.Null{}, "stageTicket", 1, serial, "cid/oid", make([]byte, 32),
+			"", "", "Concert", "2025-01-01", "1", strconv.Itoa(serial), "A", 5*gasUnit)
+		token := []byte("1-" + strconv.Itoa(serial))
+		nftInv.Invoke(t, stackitem.NewBuffer(token), "mint", owner, 1)
+		lot = append(lot, token)
+	}
+	return lot
+}
+
+func (env *testEnv) ownerOf(t *testing.T, token any) util.Uint160 {
+	stack, err := env.e.CommitteeInvoker(env.nft).TestInvoke(t, "ownerOf", token)
+	require.NoError(t, err)
+	data, err := stack.Pop().Item().TryBytes()
+	require.NoError(t, err)
+	owner, err := util.Uint160DecodeBytesBE(data)
+	require.NoError(t, err)
+	return owner
+}
+
+// gasPaid returns GAS transferred by the contract to each recipient in the transaction.
+func (env *testEnv) gasPaid(t *testing.T, h util.Uint256, from util.Uint160) map[util.Uint160]int64 {
+	gasHash := env.e.NativeHash(t, nativenames.Gas)
+	paid := make(map[util.Uint160]int64)
+	for _, ev := range env.e.GetTxExecResult(t, h).Events {
+		if !ev.ScriptHash.Equals(gasHash) || ev.Name != "Transfer" {
+			continue
+		}
+		items := ev.Item.Value().([]stackitem.Item)
+		sender, err := items[0].TryBytes()
+		if err != nil || string(sender) != string(from.BytesBE()) {
+			continue // GAS minted for fees has no sender
+		}
+		to, err := items[1].TryBytes()
+		require.NoError(t, err)
+		recipient, err := util.Uint160DecodeBytesBE(to)
+		require.NoError(t, err)
+		amount, err := items[2].TryInteger()
+		require.NoError(t, err)
+		paid[recipient] += amount.Int64()
+	}
+	return paid
+}
+
+// lastInfo returns the last "info" notification of the auction in the transaction.
+func (env *testEnv) lastInfo(t *testing.T, h util.Uint256) string {
+	var info string
+	for _, ev := range env.e.GetTxExecResult(t, h).Events {
+		if ev.ScriptHash.Equals(env.auction) && ev.Name == "info" {
+			data, err := ev.Item.Value().([]stackitem.Item)[0].TryBytes()
+			require.NoError(t, err)
+			info = string(data)
+		}
+	}
+	return info
+}
+
+func TestMultiUnitAllocation(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer := e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 4)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "startMultiUnit", organizer.ScriptHash(), lot, 2*gasUnit, true)
+
+	a, b, c, d := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	bid := func(bidder neotest.Signer, quantity int, price int) {
+		e.NewInvoker(env.auction, bidder).Invoke(t, stackitem.Null{}, "bidUnits", bidder.ScriptHash(), quantity, price)
+	}
+	bid(a, 2, 5*gasUnit)
+	bid(b, 1, 4*gasUnit)
+	bid(c, 2, 4*gasUnit) // same price as b, but later: gets the last unit only
+	bid(d, 1, 3*gasUnit) // no units left
+
+	h := e.NewInvoker(env.auction, organizer).Invoke(t, a.ScriptHash(), "finish", organizer.ScriptHash())
+
+	require.Equal(t, a.ScriptHash(), env.ownerOf(t, lot[0]))
+	require.Equal(t, a.ScriptHash(), env.ownerOf(t, lot[1]))
+	require.Equal(t, b.ScriptHash(), env.ownerOf(t, lot[2]))
+	require.Equal(t, c.ScriptHash(), env.ownerOf(t, lot[3]))
+
+	// all winners pay the clearing price, the lowest price of the winning bids
+	require.Contains(t, env.lastInfo(t, h), "Units sold: 4, clearing price: "+strconv.Itoa(4*gasUnit))
+
+	paid := env.gasPaid(t, h, env.auction)
+	require.Equal(t, map[util.Uint160]int64{
+		a.ScriptHash():         2 * gasUnit, // 2 units at 5, paid 2 at 4
+		c.ScriptHash():         4 * gasUnit, // 2 units at 4, filled 1
+		d.ScriptHash():         3 * gasUnit, // losing bid is returned
+		organizer.ScriptHash(): 16 * gasUnit,
+	}, paid)
+	e.CheckGASBalance(t, env.auction, big.NewInt(0))
+}
+
+func TestMultiUnitReplacedBid(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer := e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "startMultiUnit", organizer.ScriptHash(), lot, gasUnit, true)
+
+	a, b, c := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	bid := func(bidder neotest.Signer, quantity int, price int) util.Uint256 {
+		return e.NewInvoker(env.auction, bidder).Invoke(t, stackitem.Null{}, "bidUnits", bidder.ScriptHash(), quantity, price)
+	}
+	bid(a, 1, 2*gasUnit)
+	bid(b, 1, 2*gasUnit)
+	bid(c, 1, 2*gasUnit)
+
+	// the new bid of a replaces the previous one, which is returned, and is the latest now
+	h := bid(a, 1, 2*gasUnit)
+	require.Equal(t, map[util.Uint160]int64{a.ScriptHash(): 2 * gasUnit}, env.gasPaid(t, h, env.auction))
+
+	h = e.NewInvoker(env.auction, organizer).Invoke(t, b.ScriptHash(), "finish", organizer.ScriptHash())
+
+	require.Equal(t, b.ScriptHash(), env.ownerOf(t, lot[0]))
+	require.Equal(t, c.ScriptHash(), env.ownerOf(t, lot[1]))
+	require.Equal(t, map[util.Uint160]int64{
+		a.ScriptHash():         2 * gasUnit,
+		organizer.ScriptHash(): 4 * gasUnit,
+	}, env.gasPaid(t, h, env.auction))
+}
+
+// deployFrostfsID deploys FrostfsID stub, it registers itself in NNS as frostfsid.frostfs.
+func (env *testEnv) deployFrostfsID(t *testing.T) *neotest.ContractInvoker {
+	return env.e.CommitteeInvoker(env.deploy(t, "../frostfsid", nil))
+}
+
+func TestFrostfsIDAttribute(t *testing.T) {
+	const attrError = "bidder doesn't have required FrostfsID attribute kyc"
+
+	// each case starts the auction of its mode for the lot and returns the method
+	// making the bet of the bidder with its arguments
+	testCases := []struct {
+		name  string
+		start func(inv *neotest.ContractInvoker, organizer util.Uint160, lot []any)
+		bet   func(bidder util.Uint160) (string, []any)
+	}{
+		{
+			name: "makeBet",
+			start: func(inv *neotest.ContractInvoker, organizer util.Uint160, lot []any) {
+				inv.Invoke(t, stackitem.Null{}, "start", organizer, lot, 1, false, []any{}, 0,
+					nil, 0, "kyc", "passed", nil, 0, "", "", "", nil)
+			},
+			bet: func(bidder util.Uint160) (string, []any) {
+				return "makeBet", []any{bidder, 2}
+			},
+		},
+		{
+			name: "bidUnits",
+			start: func(inv *neotest.ContractInvoker, organizer util.Uint160, lot []any) {
+				inv.Invoke(t, stackitem.Null{}, "startMultiUnit", organizer, lot, 1, false)
+				inv.Invoke(t, stackitem.Null{}, "requireAttribute", organizer, "kyc", "passed")
+			},
+			bet: func(bidder util.Uint160) (string, []any) {
+				return "bidUnits", []any{bidder, 1, 2}
+			},
+		},
+		{
+			name: "enterRaffle",
+			start: func(inv *neotest.ContractInvoker, organizer util.Uint160, lot []any) {
+				inv.Invoke(t, stackitem.Null{}, "startRaffle", organizer, lot, 0, 3600_000)
+				inv.Invoke(t, stackitem.Null{}, "requireAttribute", organizer, "kyc", "passed")
+			},
+			bet: func(bidder util.Uint160) (string, []any) {
+				return "enterRaffle", []any{bidder}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			e := env.e
+			frostfsID := env.deployFrostfsID(t)
+
+			organizer, eligible, other := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t) // other has no attribute
+			frostfsID.Invoke(t, stackitem.Null{}, "setSubjectKV", eligible.ScriptHash(), "kyc", "passed")
+
+			lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+			tc.start(e.NewInvoker(env.auction, organizer), organizer.ScriptHash(), lot)
+
+			method, args := tc.bet(other.ScriptHash())
+			e.NewInvoker(env.auction, other).InvokeFail(t, attrError, method, args...)
+
+			method, args = tc.bet(eligible.ScriptHash())
+			e.NewInvoker(env.auction, eligible).Invoke(t, stackitem.Null{}, method, args...)
+		})
+	}
+}
+
+func TestWinnerBondWithoutPaidBets(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer := e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "start", organizer.ScriptHash(), lot, 1, false, []any{}, gasUnit,
+		nil, 0, "", "", nil, 0, "", "", "", nil)
+
+	a, b := e.NewAccount(t), e.NewAccount(t)
+	for _, bidder := range []neotest.Signer{a, b} {
+		e.NewInvoker(env.auction, bidder).Invoke(t, stackitem.Null{}, "depositBond", bidder.ScriptHash())
+	}
+	e.NewInvoker(env.auction, a).Invoke(t, stackitem.Null{}, "makeBet", a.ScriptHash(), 2)
+	e.NewInvoker(env.auction, b).Invoke(t, stackitem.Null{}, "makeBet", b.ScriptHash(), 3)
+
+	// the winner pays outside of the contract, its bond goes to the organizer
+	h := e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.NewBuffer(b.ScriptHash().BytesBE()), "finish", organizer.ScriptHash())
+	require.Equal(t, map[util.Uint160]int64{
+		a.ScriptHash():         gasUnit,
+		organizer.ScriptHash(): gasUnit,
+	}, env.gasPaid(t, h, env.auction))
+}
+
+func TestPaymentTokenAllowlist(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+	neoHash := e.NativeHash(t, nativenames.Neo)
+
+	organizer := e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+	startArgs := func(token any) []any {
+		return []any{organizer.ScriptHash(), lot, 1, true, []any{}, 0, nil, 0, "", "", token, 0, "", "", "", nil}
+	}
+	inv := e.NewInvoker(env.auction, organizer)
+	inv.InvokeFail(t, "payment token is not supported", "start", startArgs(neoHash)...)
+
+	admin := e.CommitteeInvoker(env.auction)
+	admin.Invoke(t, stackitem.Null{}, "addPaymentToken", neoHash)
+	admin.Invoke(t, true, "isPaymentToken", neoHash)
+	inv.Invoke(t, stackitem.Null{}, "start", startArgs(neoHash)...)
+}
+
+func TestPaidLotEscrowed(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer, bidder := e.NewAccount(t), e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "start", organizer.ScriptHash(), lot, 1, true, []any{}, 0,
+		nil, 0, "", "", nil, 0, "", "", "", nil)
+
+	// the organizer can't move the lot away from the running paid auction
+	for _, token := range lot {
+		require.Equal(t, env.auction, env.ownerOf(t, token))
+	}
+	e.NewInvoker(env.nft, organizer).Invoke(t, false, "transfer", organizer.ScriptHash(), lot[0], nil)
+
+	e.NewInvoker(env.auction, bidder).Invoke(t, stackitem.Null{}, "makeBet", bidder.ScriptHash(), 2*gasUnit)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.NewBuffer(bidder.ScriptHash().BytesBE()), "finish", organizer.ScriptHash())
+	for _, token := range lot {
+		require.Equal(t, bidder.ScriptHash(), env.ownerOf(t, token))
+	}
+}
+
+func TestUpdateRequiresAdmin(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	stranger := e.NewAccount(t)
+	e.NewInvoker(env.auction, stranger).InvokeFail(t, "not witnessed by admin", "update", []byte{1}, []byte{2}, []any{stranger.ScriptHash()})
+}
+
+func TestMarketListing(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+	market := env.deploy(t, "../market", nil)
+
+	seller, buyer, recipient := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	tokens := env.mintTickets(t, seller.ScriptHash(), 2)
+	e.CommitteeInvoker(env.nft).Invoke(t, stackitem.Null{}, "setRoyalty", 1, recipient.ScriptHash(), 1000)
+
+	sellerInv := e.NewInvoker(market, seller)
+	sellerInv.Invoke(t, stackitem.Null{}, "list", seller.ScriptHash(), tokens[0], 3*gasUnit)
+	sellerInv.Invoke(t, stackitem.Null{}, "list", seller.ScriptHash(), tokens[1], 3*gasUnit)
+	require.Equal(t, market, env.ownerOf(t, tokens[0]))
+	e.NewInvoker(market, buyer).InvokeFail(t, "only seller can delist the ticket", "delist", seller.ScriptHash(), tokens[1])
+
+	// 10% royalty goes to its recipient, the rest to the seller
+	h := e.NewInvoker(market, buyer).Invoke(t, stackitem.Null{}, "buy", buyer.ScriptHash(), tokens[0])
+	require.Equal(t, map[util.Uint160]int64{
+		recipient.ScriptHash(): 3 * gasUnit / 10,
+		seller.ScriptHash():    3 * gasUnit * 9 / 10,
+	}, env.gasPaid(t, h, buyer.ScriptHash()))
+	require.Equal(t, buyer.ScriptHash(), env.ownerOf(t, tokens[0]))
+	var royaltyEvent []stackitem.Item
+	for _, ev := range e.GetTxExecResult(t, h).Events {
+		if ev.ScriptHash.Equals(market) && ev.Name == "RoyaltiesTransferred" {
+			royaltyEvent = ev.Item.Value().([]stackitem.Item)
+		}
+	}
+	require.Equal(t, []stackitem.Item{
+		stackitem.NewByteArray(e.NativeHash(t, nativenames.Gas).BytesBE()),
+		stackitem.NewByteArray(recipient.ScriptHash().BytesBE()),
+		stackitem.NewByteArray(buyer.ScriptHash().BytesBE()),
+		stackitem.NewByteArray(tokens[0].([]byte)),
+		stackitem.Make(3 * gasUnit / 10),
+	}, royaltyEvent)
+
+	sellerInv.Invoke(t, stackitem.Null{}, "delist", seller.ScriptHash(), tokens[1])
+	require.Equal(t, seller.ScriptHash(), env.ownerOf(t, tokens[1]))
+}
+
+func TestMarketOffers(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+	market := env.deploy(t, "../market", nil)
+	gasHash := e.NativeHash(t, nativenames.Gas)
+
+	owner, offerer, other := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	tokens := env.mintTickets(t, owner.ScriptHash(), 2)
+
+	// GAS is accepted only as an offer price
+	e.NewInvoker(gasHash, offerer).InvokeFail(t, "GAS is accepted only for offers", "transfer",
+		offerer.ScriptHash(), market, gasUnit, tokens[0])
+
+	e.NewInvoker(market, offerer).Invoke(t, stackitem.Null{}, "makeOffer", offerer.ScriptHash(), tokens[0], 2*gasUnit, 60_000)
+	e.NewInvoker(market, other).Invoke(t, stackitem.Null{}, "makeOffer", other.ScriptHash(), tokens[0], gasUnit, 60_000)
+	e.NewInvoker(gasHash, owner).Invoke(t, 3*gasUnit, "balanceOf", market)
+
+	h := e.NewInvoker(market, owner).Invoke(t, stackitem.Null{}, "acceptOffer", owner.ScriptHash(), tokens[0], offerer.ScriptHash())
+	require.Equal(t, map[util.Uint160]int64{owner.ScriptHash(): 2 * gasUnit}, env.gasPaid(t, h, market))
+	require.Equal(t, offerer.ScriptHash(), env.ownerOf(t, tokens[0]))
+
+	// the ticket is sold, so the other offer can only be withdrawn
+	e.NewInvoker(market, owner).InvokeFail(t, "you're not the owner of this ticket", "acceptOffer",
+		owner.ScriptHash(), tokens[0], other.ScriptHash())
+	h = e.NewInvoker(market, other).Invoke(t, stackitem.Null{}, "withdrawOffer", other.ScriptHash(), tokens[0])
+	require.Equal(t, map[util.Uint160]int64{other.ScriptHash(): gasUnit}, env.gasPaid(t, h, market))
+	e.NewInvoker(gasHash, owner).Invoke(t, 0, "balanceOf", market)
+}
+
+func TestProxyBidding(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer := e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 1)
+	e.CommitteeInvoker(env.auction).Invoke(t, stackitem.Null{}, "setBidIncrement", gasUnit)
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "start", organizer.ScriptHash(), lot, gasUnit, true, []any{}, 0,
+		nil, 0, "", "", nil, 0, "", "", "", nil)
+
+	a, b := e.NewAccount(t), e.NewAccount(t)
+	bet := func(bidder neotest.Signer, method string, amount int) util.Uint256 {
+		return e.NewInvoker(env.auction, bidder).Invoke(t, stackitem.Null{}, method, bidder.ScriptHash(), amount)
+	}
+
+	// the whole maximum is paid, the visible bet is the minimum needed to lead
+	h := bet(a, "makeProxyBid", 10*gasUnit)
+	require.Equal(t, map[util.Uint160]int64{env.auction: 10 * gasUnit}, env.gasPaid(t, h, a.ScriptHash()))
+	require.Equal(t, "New bet = "+strconv.Itoa(2*gasUnit)+" is made by user "+address.Uint160ToString(a.ScriptHash()), env.lastInfo(t, h))
+
+	// bets under the maximum only raise the visible bet of the leader
+	h = bet(b, "makeBet", 4*gasUnit)
+	require.Empty(t, env.gasPaid(t, h, b.ScriptHash()))
+	require.Equal(t, "Bet is raised to "+strconv.Itoa(5*gasUnit)+" for user "+address.Uint160ToString(a.ScriptHash()), env.lastInfo(t, h))
+
+	h = bet(b, "makeProxyBid", 6*gasUnit)
+	require.Equal(t, "Bet is raised to "+strconv.Itoa(7*gasUnit)+" for user "+address.Uint160ToString(a.ScriptHash()), env.lastInfo(t, h))
+	e.NewInvoker(env.auction, a).InvokeFail(t, "maximum must be higher than the current one", "makeProxyBid", a.ScriptHash(), 9*gasUnit)
+
+	// the winner pays the visible bet, the rest of the maximum is returned
+	h = e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.NewBuffer(a.ScriptHash().BytesBE()), "finish", organizer.ScriptHash())
+	require.Equal(t, a.ScriptHash(), env.ownerOf(t, lot[0]))
+	require.Equal(t, map[util.Uint160]int64{
+		a.ScriptHash():         3 * gasUnit,
+		organizer.ScriptHash(): 7 * gasUnit,
+	}, env.gasPaid(t, h, env.auction))
+	e.CheckGASBalance(t, env.auction, big.NewInt(0))
+}
+
+func TestRaffle(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer := e.NewAccount(t)
+	a, b, c, late := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 2)
+	// blocks of the test chain are 1 ms apart, each transaction is in its own block,
+	// registration is open for the next 5 blocks
+	e.NewInvoker(env.auction, organizer).Invoke(t, stackitem.Null{}, "startRaffle", organizer.ScriptHash(), lot, gasUnit, 5)
+	for _, token := range lot {
+		require.Equal(t, env.auction, env.ownerOf(t, token))
+	}
+
+	enter := func(entrant neotest.Signer) {
+		h := e.NewInvoker(env.auction, entrant).Invoke(t, stackitem.Null{}, "enterRaffle", entrant.ScriptHash())
+		require.Equal(t, map[util.Uint160]int64{env.auction: gasUnit}, env.gasPaid(t, h, entrant.ScriptHash()))
+	}
+	enter(a)
+	enter(b)
+	e.NewInvoker(env.auction, a).InvokeFail(t, "you have already entered the raffle", "enterRaffle", a.ScriptHash())
+	enter(c)
+
+	finish := e.NewInvoker(env.auction, organizer)
+	finish.InvokeFail(t, "raffle registration is not closed yet", "finish", organizer.ScriptHash())
+	e.NewInvoker(env.auction, late).InvokeFail(t, "raffle registration is closed", "enterRaffle", late.ScriptHash())
+
+	// winners are random, so the result is checked against the owners of the tickets
+	tx := finish.PrepareInvoke(t, "finish", organizer.ScriptHash())
+	e.AddNewBlock(t, tx)
+	h := tx.Hash()
+	res := e.CheckHalt(t, h)
+	require.Contains(t, env.lastInfo(t, h), "Raffle has been finished. Entries: 3, tickets given: 2")
+
+	// each winner gets one ticket, the entry of the other one is returned
+	entrants := []util.Uint160{a.ScriptHash(), b.ScriptHash(), c.ScriptHash()}
+	first, second := env.ownerOf(t, lot[0]), env.ownerOf(t, lot[1])
+	require.Contains(t, entrants, first)
+	require.Contains(t, entrants, second)
+	require.NotEqual(t, first, second)
+	winner, err := res.Stack[0].TryBytes()
+	require.NoError(t, err)
+	require.Equal(t, first.BytesBE(), winner) // the winner of the first ticket is returned
+
+	paid := env.gasPaid(t, h, env.auction)
+	require.Equal(t, int64(2*gasUnit), paid[organizer.ScriptHash()])
+	delete(paid, organizer.ScriptHash())
+	require.Len(t, paid, 1)
+	for loser, amount := range paid {
+		require.Contains(t, entrants, loser)
+		require.NotContains(t, []util.Uint160{first, second}, loser)
+		require.Equal(t, int64(gasUnit), amount)
+	}
+	e.CheckGASBalance(t, env.auction, big.NewInt(0))
+}
+
+func TestSettlement(t *testing.T) {
+	env := newTestEnv(t)
+	e := env.e
+
+	organizer, winner, stranger := e.NewAccount(t), e.NewAccount(t), e.NewAccount(t)
+	lot := env.mintTickets(t, organizer.ScriptHash(), 3)
+	admin := e.CommitteeInvoker(env.auction)
+	admin.Invoke(t, stackitem.Null{}, "setSettlementWindow", 10) // blocks of the test chain are 1 ms apart
+
+	inv := e.NewInvoker(env.auction, organizer)
+	sell := func(token any) {
+		inv.Invoke(t, stackitem.Null{}, "start", organizer.ScriptHash(), []any{token}, 1, true, []any{}, 0,
+			nil, 0, "", "", nil, 0, "", "", "", nil)
+		e.NewInvoker(env.auction, winner).Invoke(t, stackitem.Null{}, "makeBet", winner.ScriptHash(), 2*gasUnit)
+		h := inv.Invoke(t, stackitem.NewBuffer(winner.ScriptHash().BytesBE()), "finish", organizer.ScriptHash())
+		require.Empty(t, env.gasPaid(t, h, env.auction))
+		require.Equal(t, env.auction, env.ownerOf(t, token))
+	}
+	claim := e.NewInvoker(env.auction, stranger)
+
+	t.Run("disputed", func(t *testing.T) {
+		sell(lot[0])
+		claim.InvokeFail(t, "dispute window is not closed yet", "claim", 1)
+		e.NewInvoker(env.auction, stranger).InvokeFail(t, "only the winner can dispute the settlement", "dispute", stranger.ScriptHash(), 1, "fake")
+		e.NewInvoker(env.auction, winner).Invoke(t, stackitem.Null{}, "dispute", winner.ScriptHash(), 1, "ticket is redeemed")
+		e.NewInvoker(env.auction, winner).InvokeFail(t, "settlement is already disputed", "dispute", winner.ScriptHash(), 1, "again")
+
+		e.GenerateNewBlocks(t, 10)
+		claim.InvokeFail(t, "settlement is disputed, wait for the arbiter", "claim", 1)
+		e.NewInvoker(env.auction, stranger).InvokeFail(t, "not witnessed by admin", "resolve", 1, true)
+
+		h := admin.Invoke(t, stackitem.Null{}, "resolve", 1, true)
+		require.Equal(t, map[util.Uint160]int64{winner.ScriptHash(): 2 * gasUnit}, env.gasPaid(t, h, env.auction))
+		require.Equal(t, organizer.ScriptHash(), env.ownerOf(t, lot[0]))
+	})
+
+	t.Run("claimed", func(t *testing.T) {
+		sell(lot[1])
+		e.GenerateNewBlocks(t, 10)
+		e.NewInvoker(env.auction, winner).InvokeFail(t, "dispute window is closed", "dispute", winner.ScriptHash(), 2, "late")
+
+		h := claim.Invoke(t, stackitem.Null{}, "claim", 2)
+		require.Equal(t, map[util.Uint160]int64{organizer.ScriptHash(): 2 * gasUnit}, env.gasPaid(t, h, env.auction))
+		require.Equal(t, winner.ScriptHash(), env.ownerOf(t, lot[1]))
+		claim.InvokeFail(t, "settlement 2 is not found", "claim", 2)
+	})
+
+	t.Run("void", func(t *testing.T) {
+		sell(lot[2])
+		e.CommitteeInvoker(env.nft).Invoke(t, stackitem.Null{}, "cancelCollection", 1)
+
+		// the lot of the cancelled event is refunded at once
+		h := claim.Invoke(t, stackitem.Null{}, "claim", 3)
+		require.Equal(t, map[util.Uint160]int64{winner.ScriptHash(): 2 * gasUnit}, env.gasPaid(t, h, env.auction))
+		require.Equal(t, organizer.ScriptHash(), env.ownerOf(t, lot[2]))
+	})
+	e.CheckGASBalance(t, env.auction, big.NewInt(0))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/vmstate"
 	"go.uber.org/zap"
 )
 
@@ -22,11 +23,15 @@ func (s *Server) proceedMainTxFinishAuction(nAct *notary.Actor, notaryEvent *res
 		zap.String("main", mainHash.String()), zap.String("fb", fallbackHash.String()),
 		zap.Uint32("vub", vub))
 
-	_, err = nAct.Wait(mainHash, fallbackHash, vub, err)
+	res, err := nAct.Wait(mainHash, fallbackHash, vub, err)
 	if err != nil {
 		return fmt.Errorf("wait: %w", err)
 	}
 
+	if res.Container.Equals(mainHash) && res.VMState == vmstate.Halt {
+		s.index.finish()
+	}
+
 	return nil
 }
 
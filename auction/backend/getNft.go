@@ -1,43 +1,62 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
 	"git.frostfs.info/TrueCloudLab/frostfs-sdk-go/object"
 	"git.frostfs.info/TrueCloudLab/frostfs-sdk-go/pool"
 	"git.frostfs.info/TrueCloudLab/frostfs-sdk-go/user"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
 	"github.com/nspcc-dev/neo-go/pkg/neorpc/result"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/vmstate"
 	"go.uber.org/zap"
 )
 
-func validateNotaryRequestGetNft(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, string, error) {
+func validateNotaryRequestGetNft(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, int, error) {
 	args, contractHash, err := validateNotaryRequestPreProcessing(req)
 	if err != nil {
-		return util.Uint160{}, "", err
+		return util.Uint160{}, 0, err
 	}
 
 	contractHashExpected := s.nftHash
 
 	if !contractHash.Equals(contractHashExpected) {
-		return util.Uint160{}, "", fmt.Errorf("unexpected contract hash: %s", contractHash)
+		return util.Uint160{}, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
 	}
 
 	// аргументы лежат в обратном порядке (как мы их передаем, только наоборот)
 	if len(args) != 2 { // mint принимает ровно 2 аргумента
-		return util.Uint160{}, "", fmt.Errorf("invalid param length: %d", len(args))
+		return util.Uint160{}, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	collectionID, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not parse collection id: %w", err)
 	}
 
 	sh, err := util.Uint160DecodeBytesBE(args[1].Param())
 
-	return sh, string(args[0].Param()), err
+	return sh, int(collectionID), err
 }
 
-func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, notaryEvent *result.NotaryRequestEvent, tokenName string) error {
+func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, notaryEvent *result.NotaryRequestEvent, collectionID int) error {
+	// сначала кладем билет во frost fs и подготавливаем его данные в контракте nft: mint без подготовленных данных
+	// не пройдет, поэтому токен не может появиться без адреса и метаданных
+	if err := s.stageTicket(ctx, collectionID); err != nil {
+		return fmt.Errorf("stage ticket: %w", err)
+	}
+
 	err := nAct.Sign(notaryEvent.NotaryRequest.MainTransaction)
 	if err != nil {
 		return fmt.Errorf("sign: %w", err)
@@ -54,7 +73,33 @@ func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, no
 		return fmt.Errorf("wait: %w", err)
 	}
 
-	url := s.apiUrl + tokenName
+	return nil
+}
+
+// stageTicket fetches JSON of the next ticket of the collection, puts it to FrostFS
+// and stages its address, content hash and metadata in the nft contract for the following mint.
+func (s *Server) stageTicket(ctx context.Context, collectionID int) error {
+	col, err := s.getCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	if col.minted >= col.maxSupply {
+		return fmt.Errorf("collection %d is sold out", collectionID)
+	}
+	cancelled, err := unwrap.Bool(s.act.Call(s.nftHash, "isCancelled", collectionID))
+	if err != nil {
+		return fmt.Errorf("call isCancelled %d: %w", collectionID, err)
+	}
+	if cancelled { // билеты отмененного мероприятия не выпускаются, не загружаем их во frost fs
+		return fmt.Errorf("collection %d is cancelled", collectionID)
+	}
+	serial := col.minted + 1
+
+	source := col.source
+	if source == "" {
+		source = s.apiUrl
+	}
+	url := source + strconv.Itoa(serial)
 
 	resp, err := http.Get(url)
 	if err != nil {
@@ -65,6 +110,19 @@ func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, no
 			s.log.Error("close response bode", zap.Error(err))
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get url '%s': unexpected status %s", url, resp.Status)
+	}
+
+	ticketData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read ticket '%s': %w", url, err)
+	}
+
+	meta, err := parseTicketMetadata(ticketData)
+	if err != nil {
+		return fmt.Errorf("parse ticket '%s': %w", url, err)
+	}
 
 	var ownerID user.ID
 	user.IDFromKey(&ownerID, s.acc.PrivateKey().PrivateKey.PublicKey)
@@ -74,7 +132,7 @@ func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, no
 	obj.SetOwnerID(ownerID)
 
 	var prm pool.PrmObjectPut
-	prm.SetPayload(resp.Body)
+	prm.SetPayload(bytes.NewReader(ticketData))
 	prm.SetHeader(*obj)
 
 	objID, err := s.p.PutObject(ctx, prm)
@@ -85,16 +143,139 @@ func (s *Server) proceedMainTxGetNft(ctx context.Context, nAct *notary.Actor, no
 	addr := s.cnrID.EncodeToString() + "/" + objID.ObjectID.EncodeToString()
 	s.log.Info("put object", zap.String("url", url), zap.String("address", addr))
 
-	_, err = s.act.Wait(s.act.SendCall(s.nftHash, "setAddress", tokenName, addr)) // добавляем адрес токену. После того, как произошел mint, заполнены у нового
-	// nft будут поля, кроме address. Он будет добавляться отдельно здесь, после того, как токен создался.
-	// Потому что пользователь должен знать, какую nft он хочет выписать
+	contentHash := sha256.Sum256(ticketData)
+	res, err := s.act.Wait(s.act.SendCall(s.nftHash, "stageTicket", collectionID, serial, addr, contentHash[:], meta.Description, meta.Image,
+		meta.EventName, meta.Date, meta.Row, meta.Seat, meta.Category, meta.FaceValue))
 	if err != nil {
-		return fmt.Errorf("wait setAddress: %w", err)
+		return fmt.Errorf("wait stageTicket: %w", err)
+	}
+	if res.VMState != vmstate.Halt {
+		return fmt.Errorf("stageTicket failed: %s", res.FaultException)
 	}
 
 	return nil
 }
 
-func (s *Server) checkNotaryRequestGetNft(nAct *notary.Actor, tokenName string) (bool, error) {
-	return true, nil
+// ticketMetadata is the part of the ticket JSON that is stored on-chain in the nft.
+type ticketMetadata struct {
+	Description string
+	Image       string
+	EventName   string
+	Date        string
+	Row         string
+	Seat        string
+	Category    string
+	FaceValue   int // in GAS fractions
+}
+
+// faceValueDecimals is the number of decimals of GAS, face value is stored in its fractions.
+const faceValueDecimals = 8
+
+// parseTicketMetadata extracts ticket fields from the JSON returned by ticket API.
+// Numeric fields (row, seat, price) are accepted as well as strings,
+// face value is taken from "faceValue" or "price".
+func parseTicketMetadata(data []byte) (ticketMetadata, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return ticketMetadata{}, err
+	}
+
+	field := func(name string) string {
+		v, ok := raw[name]
+		if !ok || v == nil {
+			return ""
+		}
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return fmt.Sprint(v)
+	}
+
+	meta := ticketMetadata{
+		Description: field("description"),
+		Image:       field("image"),
+		EventName:   field("eventName"),
+		Date:        field("date"),
+		Row:         field("row"),
+		Seat:        field("seat"),
+		Category:    field("category"),
+	}
+
+	faceValue := field("faceValue")
+	if faceValue == "" {
+		faceValue = field("price")
+	}
+	if faceValue != "" {
+		// json holds the price in GAS, the token keeps it in GAS fractions
+		// like bets and price caps do
+		price, err := fixedn.FromString(faceValue, faceValueDecimals)
+		if err != nil {
+			return ticketMetadata{}, fmt.Errorf("invalid face value '%s': %w", faceValue, err)
+		}
+		if price.Sign() < 0 || !price.IsInt64() {
+			return ticketMetadata{}, fmt.Errorf("invalid face value '%s'", faceValue)
+		}
+		meta.FaceValue = int(price.Int64())
+	}
+
+	if meta.Description == "" && meta.EventName != "" {
+		meta.Description = meta.EventName
+		if meta.Row != "" && meta.Seat != "" {
+			meta.Description += ", row " + meta.Row + ", seat " + meta.Seat
+		}
+	}
+
+	return meta, nil
+}
+
+// checkNotaryRequestGetNft checks that the collection exists, isn't cancelled and
+// isn't sold out, otherwise fallback transaction is sent.
+func (s *Server) checkNotaryRequestGetNft(nAct *notary.Actor, collectionID int) (bool, error) {
+	col, err := s.getCollection(collectionID)
+	if err != nil {
+		return false, nil // нет такой серии билетов, отправляем fallback
+	}
+	cancelled, err := unwrap.Bool(s.act.Call(s.nftHash, "isCancelled", collectionID))
+	if err != nil {
+		return false, fmt.Errorf("call isCancelled %d: %w", collectionID, err)
+	}
+
+	return !cancelled && col.minted < col.maxSupply, nil
+}
+
+// collectionInfo is a part of nft Collection structure used by the backend.
+type collectionInfo struct {
+	source    string
+	maxSupply int
+	minted    int
+}
+
+// getCollection reads the ticket collection from the nft contract.
+func (s *Server) getCollection(id int) (collectionInfo, error) {
+	items, err := unwrap.Array(s.act.Call(s.nftHash, "getCollection", id))
+	if err != nil {
+		return collectionInfo{}, fmt.Errorf("call getCollection %d: %w", id, err)
+	}
+	if len(items) < 6 {
+		return collectionInfo{}, fmt.Errorf("unexpected collection structure size: %d", len(items))
+	}
+
+	source, err := items[3].TryBytes()
+	if err != nil {
+		return collectionInfo{}, fmt.Errorf("collection source: %w", err)
+	}
+	maxSupply, err := items[4].TryInteger()
+	if err != nil {
+		return collectionInfo{}, fmt.Errorf("collection max supply: %w", err)
+	}
+	minted, err := items[5].TryInteger()
+	if err != nil {
+		return collectionInfo{}, fmt.Errorf("collection minted: %w", err)
+	}
+
+	return collectionInfo{
+		source:    string(source),
+		maxSupply: int(maxSupply.Int64()),
+		minted:    int(minted.Int64()),
+	}, nil
 }
@@ -15,6 +15,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"syscall"
 	"time"
@@ -91,12 +92,15 @@ type Server struct {
 	gasAct      *nep17.Token
 	nftHash     util.Uint160
 	auctionHash util.Uint160
+	marketHash  util.Uint160
 	nnsHash     util.Uint160
 	cnrID       cid.ID // Id контейнера в frost fs для хранения данных
 	log         *zap.Logger
 	rpcCli      *rpcclient.Client
 	sub         subscriber.Subscriber // подписчик на события bc
 	apiUrl      string
+	index       *auctionIndex  // аукционы, запущенные через backend, для поиска
+	limits      metadataLimits // ограничения длины строк контракта auction
 }
 
 func NewServer(ctx context.Context) (*Server, error) {
@@ -141,6 +145,16 @@ func NewServer(ctx context.Context) (*Server, error) {
 		return nil, err
 	}
 
+	contractMarketHash, err := ParseNnsResolve("market.auc", contractNnsHash, act)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := readLimits(act, contractAuctionHash) // ограничения длины метаданных берем из контракта auction
+	if err != nil {
+		return nil, err
+	}
+
 	ticketApiUrl := viper.GetString(cfgTicketApiUrl)
 
 	var cnrID cid.ID
@@ -188,12 +202,15 @@ func NewServer(ctx context.Context) (*Server, error) {
 		rpcCli:      rpcCli,
 		nftHash:     contractNftHash,
 		auctionHash: contractAuctionHash,
+		marketHash:  contractMarketHash,
 		nnsHash:     contractNnsHash,
 		gasAct:      nep17.New(act, gas.Hash),
 		cnrID:       cnrID,
 		log:         log,
 		sub:         sub,
 		apiUrl:      ticketApiUrl,
+		index:       new(auctionIndex),
+		limits:      limits,
 	}, nil
 }
 
@@ -231,6 +248,17 @@ func (s *Server) Listen(ctx context.Context) error {
 		return fmt.Errorf("notary backend deposit: %w", err)
 	}
 
+	// текущий аукцион мог начаться до запуска backend, добавляем его в индекс
+	if state, err := unwrap.UTF8String(s.act.Call(s.auctionHash, "getState")); err == nil && state != "none" {
+		organizer, err := unwrap.Uint160(s.act.Call(s.auctionHash, "getOrganizer"))
+		if err == nil {
+			err = s.indexCurrentAuction(util.Uint256{}, organizer)
+		}
+		if err != nil {
+			s.log.Error("index current auction", zap.Error(err))
+		}
+	}
+
 	go s.runNotaryValidator(ctx) // // запускается слушатель нотариальных запросов в отдельной горутине (фоновый процесс)
 
 	// обработчики запросов, которые слушают на 5555
@@ -251,6 +279,32 @@ func (s *Server) Listen(ctx context.Context) error {
 		}
 	})
 
+	http.DefaultServeMux.HandleFunc("/revenue", func(w http.ResponseWriter, r *http.Request) { // комиссия платформы, накопленная контрактом auction
+		s.log.Info("revenue request")
+
+		token := gas.Hash // по умолчанию комиссия в GAS, ?token=<хэш> - в другом токене оплаты
+		if tokenStr := r.URL.Query().Get("token"); tokenStr != "" {
+			var err error
+			if token, err = util.Uint160DecodeStringLE(tokenStr); err != nil {
+				s.log.Error("invalid token hash", zap.String("token", tokenStr), zap.Error(err))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		res, err := unwrap.BigInt(s.act.Call(s.auctionHash, "getRevenue", token))
+		if err != nil {
+			s.log.Error("revenue error", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write([]byte(res.String())); err != nil {
+			s.log.Error("write response error", zap.Error(err))
+		}
+	})
+
 	http.DefaultServeMux.HandleFunc("/properties/{tokenID}", func(w http.ResponseWriter, r *http.Request) { // обработчик запроса "посмотреть свойства указанного nft токена"
 		s.log.Info("properties request")
 
@@ -289,6 +343,10 @@ func (s *Server) Listen(ctx context.Context) error {
 		}
 	})
 
+	http.DefaultServeMux.HandleFunc("/verify-ticket/{tokenID}", s.handleVerifyTicket) // проверка на входе, что предъявитель подписи владеет билетом
+
+	http.DefaultServeMux.HandleFunc("/auctions", s.handleAuctions) // поиск аукционов по названию, описанию и категории
+
 	http.DefaultServeMux.HandleFunc("/notary-deposit/{userAddress}", func(w http.ResponseWriter, r *http.Request) { // накинуть НД по нужному адресу (клиент
 		// этот запрос дергает, чтобы себе получить НД)
 		s.log.Info("notary-deposit request", zap.String("url", r.URL.String()))
@@ -339,7 +397,7 @@ func (s *Server) runNotaryValidator(ctx context.Context) { // слушатель
 
 			switch notaryEvent.Type {
 			case mempoolevent.TransactionAdded:
-				scriptHash, tokenName, nftIdBytes, bet, err := s.parseNotaryEvent(notaryEvent)
+				args, err := s.parseNotaryEvent(notaryEvent)
 				if err != nil {
 					s.log.Error("parse notary event", zap.Error(err))
 					continue
@@ -350,41 +408,91 @@ func (s *Server) runNotaryValidator(ctx context.Context) { // слушатель
 				var isMain bool
 				switch currentOperation {
 				case "mint":
-					isMain, err = s.checkNotaryRequestGetNft(nAct, tokenName)
+					isMain, err = s.checkNotaryRequestGetNft(nAct, args.collection)
 					if err != nil {
 						s.log.Error("check notary request mint", zap.Error(err))
 						continue
 					}
-				case "start":
-					isMain, err = s.checkNotaryRequestStartAuction(nAct, scriptHash, nftIdBytes, bet)
+				case "depositBond":
+					isMain, err = s.checkNotaryRequestDepositBond(nAct, args.sender)
+					if err != nil {
+						s.log.Error("check notary request depositBond", zap.Error(err))
+						continue
+					}
+				case "start", "startMultiUnit", "startRaffle":
+					isMain, err = s.checkNotaryRequestStartAuction(nAct)
 					if err != nil {
 						s.log.Error("check notary request start", zap.Error(err))
 						continue
 					}
-				case "makeBet":
-					isMain, err = s.checkNotaryRequestMakeBet(nAct, scriptHash, bet)
+				case "addToAllowlist", "removeFromAllowlist", "requireAttribute":
+					isMain, err = s.checkNotaryRequestOrganizer(nAct, args.sender)
+					if err != nil {
+						s.log.Error("check notary request "+currentOperation, zap.Error(err))
+						continue
+					}
+				case "makeBet", "makeProxyBid":
+					isMain, err = s.checkNotaryRequestMakeBet(nAct, args.sender, args.bet)
 					if err != nil {
 						s.log.Error("check notary request makeBet", zap.Error(err))
 						continue
 					}
+				case "bidUnits":
+					isMain, err = s.checkNotaryRequestBidUnits(nAct, args.sender, args.quantity, args.bet)
+					if err != nil {
+						s.log.Error("check notary request bidUnits", zap.Error(err))
+						continue
+					}
+				case "enterRaffle":
+					isMain, err = s.checkNotaryRequestEnterRaffle(nAct, args.sender)
+					if err != nil {
+						s.log.Error("check notary request enterRaffle", zap.Error(err))
+						continue
+					}
 				case "finish":
-					isMain, err = s.checkNotaryRequestFinishAuction(nAct, scriptHash)
+					isMain, err = s.checkNotaryRequestFinishAuction(nAct, args.sender)
 					if err != nil {
 						s.log.Error("check notary request finish", zap.Error(err))
 						continue
 					}
+				case "dispute", "claim":
+					isMain, err = s.checkNotaryRequestSettlement(nAct, currentOperation, args.sender, args.settlement)
+					if err != nil {
+						s.log.Error("check notary request "+currentOperation, zap.Error(err))
+						continue
+					}
+				case "fundRefundPool", "claimRefund":
+					isMain, err = s.checkNotaryRequestRefund(nAct, currentOperation, args)
+					if err != nil {
+						s.log.Error("check notary request "+currentOperation, zap.Error(err))
+						continue
+					}
+				case "list", "delist", "buy":
+					isMain, err = s.checkNotaryRequestMarket(nAct, currentOperation, args.sender, args.nftID)
+					if err != nil {
+						s.log.Error("check notary request "+currentOperation, zap.Error(err))
+						continue
+					}
+				case "makeOffer", "acceptOffer", "withdrawOffer":
+					isMain, err = s.checkNotaryRequestOffer(nAct, currentOperation, args)
+					if err != nil {
+						s.log.Error("check notary request "+currentOperation, zap.Error(err))
+						continue
+					}
 				}
 
 				if isMain {
 					switch currentOperation {
 					case "mint":
-						err = s.proceedMainTxGetNft(ctx, nAct, notaryEvent, tokenName)
-					case "start":
+						err = s.proceedMainTxGetNft(ctx, nAct, notaryEvent, args.collection)
+					case "start", "startMultiUnit", "startRaffle", "addToAllowlist", "removeFromAllowlist", "requireAttribute":
 						err = s.proceedMainTxStartAuction(nAct, notaryEvent)
-					case "makeBet":
+					case "makeBet", "makeProxyBid", "bidUnits", "enterRaffle", "depositBond", "dispute", "claim", "fundRefundPool", "claimRefund":
 						err = s.proceedMainTxMakeBet(nAct, notaryEvent)
 					case "finish":
 						err = s.proceedMainTxFinishAuction(nAct, notaryEvent)
+					case "list", "delist", "buy", "makeOffer", "acceptOffer", "withdrawOffer":
+						err = s.proceedMainTxMarket(nAct, notaryEvent)
 					}
 
 				} else {
@@ -392,34 +500,101 @@ func (s *Server) runNotaryValidator(ctx context.Context) { // слушатель
 				}
 
 				if err != nil {
-					s.log.Error("proceed notary tx", zap.Bool("main", isMain), zap.String("token", tokenName), zap.Error(err))
+					s.log.Error("proceed notary tx", zap.Bool("main", isMain), zap.String("operation", currentOperation), zap.Error(err))
 				} else {
-					s.log.Info("proceed notary tx", zap.Bool("main", isMain), zap.String("token", tokenName))
+					s.log.Info("proceed notary tx", zap.Bool("main", isMain), zap.String("operation", currentOperation))
 				}
 			}
 		}
 	}
 }
 
-func (s *Server) parseNotaryEvent(notaryEvent *result.NotaryRequestEvent) (util.Uint160, string, []byte, int, error) {
+// notaryRequestArgs holds arguments of the contract call from the main
+// transaction of the notary request that are needed to check and proceed it.
+type notaryRequestArgs struct {
+	sender     util.Uint160 // user who signed the request
+	collection int          // mint, fundRefundPool: ticket collection ID
+	nftID      []byte       // list, delist, buy, offers, claimRefund: ticket ID
+	lot        [][]byte     // start, startMultiUnit, startRaffle: IDs of the lot tickets
+	bet        int          // start: initial bet, startMultiUnit: reserve price, startRaffle: entry price, makeBet, bidUnits: bet, makeProxyBid: maximum
+	quantity   int          // bidUnits: number of units
+	settlement int          // dispute, claim: settlement ID
+	price      int          // list: ticket price, makeOffer: offered price, fundRefundPool: amount
+	offerer    util.Uint160 // acceptOffer: user whose offer is accepted
+}
+
+func (s *Server) parseNotaryEvent(notaryEvent *result.NotaryRequestEvent) (notaryRequestArgs, error) {
 	if len(notaryEvent.NotaryRequest.MainTransaction.Signers) != 3 { // подписанты:  1 - backend , который за все платит, 2 - client, который принимает на свой счет nft,
 		// 3 - нотариальный контракт сам по себе, чья подпись необходима, чтобы  нотариальный запрос состоялся
-		return util.Uint160{}, "", nil, 0, errors.New("error not enough signers")
+		return notaryRequestArgs{}, errors.New("error not enough signers")
 	}
 
 	if notaryEvent.NotaryRequest.Witness.ScriptHash().Equals(s.acc.ScriptHash()) {
-		return util.Uint160{}, "", nil, 0, fmt.Errorf("ignore owned notary request: %s", notaryEvent.NotaryRequest.Hash().String())
+		return notaryRequestArgs{}, fmt.Errorf("ignore owned notary request: %s", notaryEvent.NotaryRequest.Hash().String())
 	}
 
-	scriptHash, tokenName, nftIdBytes, initBet, err := validateNotaryRequest(notaryEvent.NotaryRequest, s)
+	if err := s.validateSignerScopes(notaryEvent.NotaryRequest.MainTransaction.Signers); err != nil {
+		return notaryRequestArgs{}, fmt.Errorf("invalid signer scopes: %w", err)
+	}
+
+	return validateNotaryRequest(notaryEvent.NotaryRequest, s)
+}
+
+// signerContracts returns contracts where user witness of notary requests may be
+// used: auction, nft, market, GAS (paid auction bets and market purchases), payment
+// tokens and NEP-11 contracts allowed by auction admin.
+func (s *Server) signerContracts() []util.Uint160 {
+	contracts := []util.Uint160{s.auctionHash, s.nftHash, s.marketHash, gas.Hash}
+	// ставки и залоги аукциона переводятся в его токене оплаты. Разрешаются только токены из списка
+	// администратора auction, а не то, что вернул getPaymentToken: код токена выполняется с подписью пользователя
+	contracts = s.appendAuctionContracts(contracts, "paymentTokens")
+	// организатор переводит лот другого NEP-11 контракта на auction при старте
+	return s.appendAuctionContracts(contracts, "supportedContracts")
+}
+
+// appendAuctionContracts appends contract hashes returned by the iterator method of auction.
+func (s *Server) appendAuctionContracts(contracts []util.Uint160, method string) []util.Uint160 {
+	items, err := unwrap.Array(s.act.CallAndExpandIterator(s.auctionHash, method, 100))
 	if err != nil {
-		return util.Uint160{}, "", nil, 0, err
+		return contracts
+	}
+	for _, item := range items {
+		b, err := item.TryBytes()
+		if err != nil {
+			continue
+		}
+		h, err := util.Uint160DecodeBytesBE(b)
+		if err == nil && !slices.Contains(contracts, h) {
+			contracts = append(contracts, h)
+		}
+	}
+	return contracts
+}
+
+// validateSignerScopes checks that sponsored transaction can't use user witness outside
+// of our contracts: backend signer must have None scope, user signer CalledByEntry
+// and/or CustomContracts limited to signerContracts.
+func (s *Server) validateSignerScopes(signers []transaction.Signer) error {
+	if signers[0].Scopes != transaction.None {
+		return fmt.Errorf("sponsor signer scope must be None, got %s", signers[0].Scopes)
 	}
 
-	return scriptHash, tokenName, nftIdBytes, initBet, err
+	user := signers[1]
+	if user.Scopes&^(transaction.CalledByEntry|transaction.CustomContracts) != 0 {
+		return fmt.Errorf("user signer scope is too broad: %s", user.Scopes)
+	}
+
+	allowed := s.signerContracts()
+	for _, h := range user.AllowedContracts {
+		if !slices.Contains(allowed, h) {
+			return fmt.Errorf("user signer allows unexpected contract: %s", h.StringLE())
+		}
+	}
+
+	return nil
 }
 
-func validateNotaryRequest(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, string, []byte, int, error) {
+func validateNotaryRequest(req *payload.P2PNotaryRequest, s *Server) (notaryRequestArgs, error) {
 	var (
 		opCode opcode.Opcode // мб = PUSH, CALL, RET и тп
 		param  []byte        // параметры инструкции
@@ -432,7 +607,7 @@ func validateNotaryRequest(req *payload.P2PNotaryRequest, s *Server) (util.Uint1
 	for {
 		opCode, param, err = ctx.Next()
 		if err != nil {
-			return util.Uint160{}, "", nil, 0, fmt.Errorf("could not get next opcode in script: %w", err)
+			return notaryRequestArgs{}, fmt.Errorf("could not get next opcode in script: %w", err)
 		}
 
 		if opCode == opcode.RET {
@@ -446,27 +621,54 @@ func validateNotaryRequest(req *payload.P2PNotaryRequest, s *Server) (util.Uint1
 
 	contractMethod := string(ops[opsLen-3].param) // название метода - 3я с конца инструкция
 	currentOperation = contractMethod
-	var (
-		sh         util.Uint160
-		tokenName  string
-		nftIdBytes []byte
-		bet        int
-	)
+	var args notaryRequestArgs
 
 	switch contractMethod {
 	case "mint":
-		sh, tokenName, err = validateNotaryRequestGetNft(req, s)
+		args.sender, args.collection, err = validateNotaryRequestGetNft(req, s)
 	case "start":
-		sh, nftIdBytes, bet, err = validateNotaryRequestStartAuction(req, s)
+		args.sender, args.lot, args.bet, err = validateNotaryRequestStartAuction(req, s, true)
+	case "startMultiUnit":
+		args.sender, args.lot, args.bet, err = validateNotaryRequestStartAuction(req, s, false)
+	case "addToAllowlist", "removeFromAllowlist":
+		args.sender, err = validateNotaryRequestAllowlist(req, s)
+	case "requireAttribute":
+		args.sender, err = validateNotaryRequestRequireAttribute(req, s)
+	case "depositBond":
+		args.sender, err = validateNotaryRequestAuctionUser(req, s)
 	case "makeBet":
-		sh, bet, err = validateNotaryRequestMakeBet(req, s)
+		args.sender, args.bet, err = validateNotaryRequestMakeBet(req, s)
+	case "makeProxyBid":
+		args.sender, args.bet, err = validateNotaryRequestMakeProxyBid(req, s)
+	case "startRaffle":
+		args.sender, args.lot, args.bet, err = validateNotaryRequestStartRaffle(req, s)
+	case "bidUnits":
+		args.sender, args.quantity, args.bet, err = validateNotaryRequestBidUnits(req, s)
+	case "enterRaffle":
+		args.sender, err = validateNotaryRequestAuctionUser(req, s)
 	case "finish":
 		err = validateNotaryRequestFinishAuction(req, s)
+	case "dispute":
+		args.sender, args.settlement, err = validateNotaryRequestDispute(req, s)
+	case "claim":
+		args.settlement, err = validateNotaryRequestClaim(req, s)
+	case "fundRefundPool":
+		args.sender, args.collection, args.price, err = validateNotaryRequestFundRefundPool(req, s)
+	case "claimRefund":
+		args.sender, args.nftID, err = validateNotaryRequestClaimRefund(req, s)
+	case "list":
+		args.sender, args.nftID, args.price, err = validateNotaryRequestList(req, s)
+	case "delist", "buy", "withdrawOffer":
+		args.sender, args.nftID, err = validateNotaryRequestMarketToken(req, s)
+	case "makeOffer":
+		args.sender, args.nftID, args.price, err = validateNotaryRequestMakeOffer(req, s)
+	case "acceptOffer":
+		args.sender, args.nftID, args.offerer, err = validateNotaryRequestAcceptOffer(req, s)
 	default:
 		fmt.Printf("Unknown contractMethod: %s\n", contractMethod)
 	}
 
-	return sh, tokenName, nftIdBytes, bet, err
+	return args, err
 }
 
 func validateNotaryRequestPreProcessing(req *payload.P2PNotaryRequest) ([]Op, util.Uint160, error) {
@@ -528,6 +730,40 @@ func validateNotaryRequestPreProcessing(req *payload.P2PNotaryRequest) ([]Op, ut
 	return args, contractHash, err
 }
 
+// callArgsCount returns the number of arguments of the contract call in the
+// main transaction, arrays count as one argument unlike their opcodes.
+func callArgsCount(req *payload.P2PNotaryRequest) (int, error) {
+	ctx := vm.NewContext(req.MainTransaction.Script)
+	ops := make([]Op, 0, 20)
+	for {
+		opCode, param, err := ctx.Next()
+		if err != nil {
+			return 0, fmt.Errorf("could not get next opcode in script: %w", err)
+		}
+		if opCode == opcode.RET {
+			break
+		}
+		ops = append(ops, Op{code: opCode, param: param})
+	}
+
+	// аргументы, PUSHN и PACK (или NEWARRAY0), флаг вызова, метод, контракт и SYSCALL
+	l := len(ops)
+	if l < 5 {
+		return 0, errors.New("not contract call")
+	}
+	if ops[l-5].Code() == opcode.NEWARRAY0 {
+		return 0, nil
+	}
+	if l < 6 || ops[l-5].Code() != opcode.PACK {
+		return 0, fmt.Errorf("unexpected packing opcode: %s", ops[l-5].Code())
+	}
+	n, err := IntFromOpcode(ops[l-6])
+	if err != nil {
+		return 0, fmt.Errorf("could not parse argument len: %w", err)
+	}
+	return int(n), nil
+}
+
 func (s *Server) proceedFbTx(nAct *notary.Actor, notaryEvent *result.NotaryRequestEvent) error {
 	err := nAct.Sign(notaryEvent.NotaryRequest.FallbackTransaction)
 	if err != nil {
@@ -559,7 +795,7 @@ func parseMap(m *stackitem.Map) (map[string]string, error) {
 		kStr := string(k)
 
 		switch kStr {
-		case "id":
+		case "id", "hash":
 			res[kStr] = hex.EncodeToString(v)
 		default:
 			res[kStr] = string(v)
@@ -607,8 +843,9 @@ func (s *Server) notaryActor(userWitness transaction.Witness) *notary.Actor {
 		},
 		{
 			Signer: transaction.Signer{
-				Account: userAcc.ScriptHash(), // 2 подписант - не знаем SK clientа, т.к данная программа - backend, а не client, ставит PK clientа
-				Scopes:  transaction.Global,
+				Account:          userAcc.ScriptHash(), // 2 подписант - не знаем SK clientа, т.к данная программа - backend, а не client, ставит PK clientа
+				Scopes:           transaction.CustomContracts,
+				AllowedContracts: s.signerContracts(),
 			},
 			Account: userAcc,
 		},
@@ -644,6 +881,61 @@ func (o Op) Param() []byte {
 	return o.param
 }
 
+// BoolFromOpcodes tries to retrieve bool from the first of ops. Bool is
+// pushed as PUSHT/PUSHF optionally followed by CONVERT, the number of
+// used ops is returned.
+func BoolFromOpcodes(ops []Op) (bool, int, error) {
+	if len(ops) == 0 {
+		return false, 0, errors.New("no opcodes")
+	}
+
+	used := 1
+	if len(ops) > 1 && ops[1].Code() == opcode.CONVERT {
+		used = 2
+	}
+
+	switch code := ops[0].Code(); code {
+	case opcode.PUSHT:
+		return true, used, nil
+	case opcode.PUSHF:
+		return false, used, nil
+	default:
+		return false, 0, fmt.Errorf("unexpected BOOL opcode %s", code)
+	}
+}
+
+// BytesArrayFromOpcodes tries to retrieve array of byte strings packed by the
+// last of ops. Items are pushed in reverse order followed by their number and
+// PACK, they are returned in the original order with the number of used ops.
+// Empty array is pushed as NEWARRAY0.
+func BytesArrayFromOpcodes(ops []Op) ([][]byte, int, error) {
+	l := len(ops)
+	if l > 0 && ops[l-1].Code() == opcode.NEWARRAY0 {
+		return [][]byte{}, 1, nil
+	}
+	if l < 2 || ops[l-1].Code() != opcode.PACK {
+		return nil, 0, errors.New("no packed array")
+	}
+
+	n, err := IntFromOpcode(ops[l-2])
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not parse array len: %w", err)
+	}
+	if n < 0 || int(n) > l-2 {
+		return nil, 0, fmt.Errorf("invalid array len: %d", n)
+	}
+
+	res := make([][]byte, 0, n)
+	for i := l - 3; i >= l-2-int(n); i-- {
+		if ops[i].Code() > opcode.PUSHDATA4 || ops[i].Code() < opcode.PUSHDATA1 {
+			return nil, 0, fmt.Errorf("unexpected array item opcode %s", ops[i].Code())
+		}
+		res = append(res, ops[i].Param())
+	}
+
+	return res, int(n) + 2, nil
+}
+
 // IntFromOpcode tries to retrieve int from Op.
 func IntFromOpcode(op Op) (int64, error) {
 	switch code := op.Code(); {
@@ -682,12 +974,13 @@ func validateNestedArgs(expArgLen int64, ops []Op) error {
 	)
 
 	for i := opsLenGot - 1; i >= 0; i-- {
-		// only PUSH(also, PACK for arrays and CONVERT for booleans)
-		// codes are allowed; number of params and their content must
-		// be checked in a notary parser and a notary handler of a
+		// only PUSH(also, PACK and NEWARRAY0 for arrays and CONVERT for
+		// booleans) codes are allowed; number of params and their content
+		// must be checked in a notary parser and a notary handler of a
 		// particular contract
 		switch currentCode = ops[i].code; {
 		case currentCode <= opcode.PUSH16:
+		case currentCode == opcode.NEWARRAY0:
 		case currentCode == opcode.CONVERT:
 			if i == 0 || ops[i-1].code != opcode.PUSHT && ops[i-1].code != opcode.PUSHF {
 				return errors.New("errUnexpectedCONVERT")
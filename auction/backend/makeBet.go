@@ -1,13 +1,17 @@
 package main
 
 import (
-	"encoding/binary"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/neorpc/result"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"go.uber.org/zap"
 )
 
@@ -45,24 +49,278 @@ func validateNotaryRequestMakeBet(req *payload.P2PNotaryRequest, s *Server) (uti
 		return util.Uint160{}, 0, err
 	}
 
-	bet := int(binary.LittleEndian.Uint16(args[0].Param()))
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
 
 	if len(args) != 2 {
 		return util.Uint160{}, 0, fmt.Errorf("invalid param length: %d", len(args))
 	}
 
+	bet, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not parse bet: %w", err)
+	}
+	if bet <= 0 {
+		return util.Uint160{}, 0, fmt.Errorf("invalid bet: %d", bet)
+	}
+
+	scriptHash, err := util.Uint160DecodeBytesBE(args[1].Param())
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return scriptHash, int(bet), nil
+}
+
+func (s *Server) checkNotaryRequestMakeBet(nAct *notary.Actor, better util.Uint160, bet int) (bool, error) {
+	state, err := unwrap.UTF8String(s.act.Call(s.auctionHash, "getState"))
+	if err != nil {
+		return false, fmt.Errorf("call getState: %w", err)
+	}
+	if state != "running" { // запланированный аукцион еще не начался
+		return false, nil
+	}
+
+	return s.checkGate(better)
+}
+
+// checkGate runs the same test as auction contract for token-gated auction: the
+// bidder must hold a token of the gating contract (of its collection if it's set)
+// and have the required FrostfsID attribute.
+func (s *Server) checkGate(bidder util.Uint160) (bool, error) {
+	ok, err := s.checkAttribute(bidder)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	item, err := unwrap.Item(s.act.Call(s.auctionHash, "getGate"))
+	if err != nil {
+		return false, fmt.Errorf("call getGate: %w", err)
+	}
+	if item.Type() == stackitem.AnyT {
+		return true, nil // аукцион без ограничения
+	}
+	gate, ok := item.Value().([]stackitem.Item)
+	if !ok || len(gate) != 2 {
+		return false, fmt.Errorf("unexpected gate: %v", item)
+	}
+
+	gateBytes, err := gate[0].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("gate contract: %w", err)
+	}
+	gateHash, err := util.Uint160DecodeBytesBE(gateBytes)
+	if err != nil {
+		return false, fmt.Errorf("gate contract: %w", err)
+	}
+	gateCollection, err := gate[1].TryInteger()
+	if err != nil {
+		return false, fmt.Errorf("gate collection: %w", err)
+	}
+
+	var balance int64
+	if gateCollection.Sign() > 0 {
+		balance, err = unwrap.Int64(s.act.Call(gateHash, "balanceOfCollection", bidder, gateCollection))
+	} else {
+		balance, err = unwrap.Int64(s.act.Call(gateHash, "balanceOf", bidder))
+	}
+	if err != nil {
+		return false, nil // контракт не отвечает, ставка тоже не пройдет
+	}
+
+	return balance > 0, nil
+}
+
+// checkAttribute checks that the bidder has FrostfsID attribute required by the
+// current auction.
+func (s *Server) checkAttribute(bidder util.Uint160) (bool, error) {
+	item, err := unwrap.Item(s.act.Call(s.auctionHash, "getRequiredAttribute"))
+	if err != nil {
+		return false, fmt.Errorf("call getRequiredAttribute: %w", err)
+	}
+	if item.Type() == stackitem.AnyT {
+		return true, nil // аукцион без требований к атрибутам
+	}
+	attr, ok := item.Value().([]stackitem.Item)
+	if !ok || len(attr) != 2 {
+		return false, fmt.Errorf("unexpected required attribute: %v", item)
+	}
+	attrName, err := attr[0].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("attribute name: %w", err)
+	}
+	attrValue, err := attr[1].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("attribute value: %w", err)
+	}
+
+	// у FrostfsID в nns две TXT записи (хэш и адрес), как и контракт auction, берем адрес
+	records, err := unwrap.ArrayOfUTF8Strings(s.act.Call(s.nnsHash, "resolve", "frostfsid.frostfs", 16))
+	if err != nil {
+		return false, fmt.Errorf("resolve frostfsid: %w", err)
+	}
+	idx := slices.IndexFunc(records, func(record string) bool {
+		_, err := address.StringToUint160(record)
+		return err == nil
+	})
+	if idx < 0 {
+		return false, fmt.Errorf("frostfsid address is not found in nns")
+	}
+	frostfsidHash, _ := address.StringToUint160(records[idx])
+	value, err := unwrap.UTF8String(s.act.Call(frostfsidHash, "getSubjectKV", bidder, string(attrName)))
+	if err != nil {
+		return false, nil // атрибута нет, ставка тоже не пройдет
+	}
+
+	return value == string(attrValue), nil
+}
+
+// validateNotaryRequestMakeProxyBid validates makeProxyBid(bidder, maxBid) call of auction contract.
+func validateNotaryRequestMakeProxyBid(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, 0, err
+	}
+
 	if !contractHash.Equals(s.auctionHash) {
 		return util.Uint160{}, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
 	}
 
+	if len(args) != 2 {
+		return util.Uint160{}, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	maxBid, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not parse max bid: %w", err)
+	}
+	if maxBid <= 0 {
+		return util.Uint160{}, 0, fmt.Errorf("invalid max bid: %d", maxBid)
+	}
+
 	scriptHash, err := util.Uint160DecodeBytesBE(args[1].Param())
 	if err != nil {
 		return util.Uint160{}, 0, fmt.Errorf("could not decode script hash: %w", err)
 	}
 
-	return scriptHash, bet, err
+	return scriptHash, int(maxBid), nil
 }
 
-func (s *Server) checkNotaryRequestMakeBet(nAct *notary.Actor, better util.Uint160, bet int) (bool, error) {
-	return true, nil
+// validateNotaryRequestBidUnits validates bidUnits(bidder, quantity, price) call of auction contract.
+func validateNotaryRequestBidUnits(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, int, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, 0, 0, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, 0, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 3 {
+		return util.Uint160{}, 0, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	price, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not parse price: %w", err)
+	}
+	quantity, err := IntFromOpcode(args[1])
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not parse quantity: %w", err)
+	}
+	if price <= 0 || quantity <= 0 {
+		return util.Uint160{}, 0, 0, fmt.Errorf("invalid bet: %d units at %d", quantity, price)
+	}
+
+	scriptHash, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return scriptHash, int(quantity), int(price), nil
+}
+
+// checkNotaryRequestBidUnits checks that multi-unit auction is running, otherwise
+// fallback transaction is sent.
+func (s *Server) checkNotaryRequestBidUnits(nAct *notary.Actor, bidder util.Uint160, quantity int, price int) (bool, error) {
+	multiUnit, err := unwrap.Bool(s.act.Call(s.auctionHash, "isMultiUnit"))
+	if err != nil || !multiUnit {
+		return false, nil // нет многолотового аукциона, отправляем fallback
+	}
+
+	return s.checkGate(bidder)
+}
+
+// validateNotaryRequestAuctionUser validates enterRaffle(entrant) and depositBond(bidder)
+// calls of auction contract.
+func validateNotaryRequestAuctionUser(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 1 {
+		return util.Uint160{}, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	scriptHash, err := util.Uint160DecodeBytesBE(args[0].Param())
+	if err != nil {
+		return util.Uint160{}, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return scriptHash, nil
+}
+
+// checkNotaryRequestEnterRaffle checks that the raffle registration is open and
+// the entrant hasn't entered yet, otherwise fallback transaction is sent.
+func (s *Server) checkNotaryRequestEnterRaffle(nAct *notary.Actor, entrant util.Uint160) (bool, error) {
+	status, err := unwrap.Array(s.act.Call(s.auctionHash, "raffleStatus"))
+	if err != nil {
+		return false, fmt.Errorf("call raffleStatus: %w", err)
+	}
+	if len(status) != 4 {
+		return false, fmt.Errorf("unexpected raffle status size: %d", len(status))
+	}
+	end, err := status[3].TryInteger()
+	if err != nil {
+		return false, fmt.Errorf("raffle end: %w", err)
+	}
+	if end.Int64() < time.Now().UnixMilli() {
+		return false, nil // розыгрыш не идет или регистрация закрыта
+	}
+
+	entered, err := unwrap.Bool(s.act.Call(s.auctionHash, "isEntered", entrant))
+	if err != nil {
+		return false, fmt.Errorf("call isEntered: %w", err)
+	}
+	if entered {
+		return false, nil
+	}
+
+	return s.checkGate(entrant)
+}
+
+// checkNotaryRequestDepositBond checks that the current auction requires a bond and
+// the bidder hasn't deposited it yet, otherwise fallback transaction is sent.
+func (s *Server) checkNotaryRequestDepositBond(nAct *notary.Actor, bidder util.Uint160) (bool, error) {
+	bond, err := unwrap.Int64(s.act.Call(s.auctionHash, "getBond"))
+	if err != nil {
+		return false, fmt.Errorf("call getBond: %w", err)
+	}
+	if bond == 0 {
+		return false, nil
+	}
+
+	deposited, err := unwrap.Int64(s.act.Call(s.auctionHash, "bondOf", bidder))
+	if err != nil {
+		return false, fmt.Errorf("call bondOf: %w", err)
+	}
+
+	return deposited == 0, nil
 }
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/neorpc/result"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+func (s *Server) proceedMainTxMarket(nAct *notary.Actor, notaryEvent *result.NotaryRequestEvent) error {
+	err := nAct.Sign(notaryEvent.NotaryRequest.MainTransaction)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	mainHash, fallbackHash, vub, err := nAct.Notarize(notaryEvent.NotaryRequest.MainTransaction, nil)
+	s.log.Info("notarize sending",
+		zap.String("hash", notaryEvent.NotaryRequest.Hash().String()),
+		zap.String("main", mainHash.String()), zap.String("fb", fallbackHash.String()),
+		zap.Uint32("vub", vub))
+
+	_, err = nAct.Wait(mainHash, fallbackHash, vub, err)
+	if err != nil {
+		return fmt.Errorf("wait: %w", err)
+	}
+
+	return nil
+}
+
+// validateNotaryRequestList validates list(seller, token, price) call of market contract.
+func validateNotaryRequestList(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, 0, err
+	}
+
+	if !contractHash.Equals(s.marketHash) {
+		return util.Uint160{}, nil, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 3 { // list принимает ровно 3 аргумента
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	price, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse price: %w", err)
+	}
+	if price <= 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid price: %d", price)
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, args[1].Param(), int(price), nil
+}
+
+// validateNotaryRequestMarketToken validates delist(seller, token), buy(buyer, token)
+// and withdrawOffer(offerer, token) calls of market contract.
+func validateNotaryRequestMarketToken(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, err
+	}
+
+	if !contractHash.Equals(s.marketHash) {
+		return util.Uint160{}, nil, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 2 {
+		return util.Uint160{}, nil, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[1].Param())
+	if err != nil {
+		return util.Uint160{}, nil, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, args[0].Param(), nil
+}
+
+// validateNotaryRequestMakeOffer validates makeOffer(offerer, token, price, duration) call of market contract.
+func validateNotaryRequestMakeOffer(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, 0, err
+	}
+
+	if !contractHash.Equals(s.marketHash) {
+		return util.Uint160{}, nil, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 4 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	duration, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse duration: %w", err)
+	}
+	if duration <= 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid duration: %d", duration)
+	}
+
+	price, err := IntFromOpcode(args[1])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse price: %w", err)
+	}
+	if price <= 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid price: %d", price)
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[3].Param())
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, args[2].Param(), int(price), nil
+}
+
+// validateNotaryRequestAcceptOffer validates acceptOffer(owner, token, offerer) call of market contract.
+func validateNotaryRequestAcceptOffer(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, util.Uint160, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, util.Uint160{}, err
+	}
+
+	if !contractHash.Equals(s.marketHash) {
+		return util.Uint160{}, nil, util.Uint160{}, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 3 {
+		return util.Uint160{}, nil, util.Uint160{}, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	offerer, err := util.Uint160DecodeBytesBE(args[0].Param())
+	if err != nil {
+		return util.Uint160{}, nil, util.Uint160{}, fmt.Errorf("could not decode offerer: %w", err)
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, nil, util.Uint160{}, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, args[1].Param(), offerer, nil
+}
+
+// checkNotaryRequestMarket checks that the market call can succeed, otherwise
+// fallback transaction is sent: the seller must own the ticket to list it,
+// the ticket must be listed to be bought or delisted (by its seller only).
+func (s *Server) checkNotaryRequestMarket(nAct *notary.Actor, method string, user util.Uint160, token []byte) (bool, error) {
+	if method != "delist" && s.isVoid(token) {
+		return false, nil // билет отмененного мероприятия
+	}
+	if method == "list" {
+		owner, err := unwrap.Uint160(s.act.Call(s.nftHash, "ownerOf", token))
+		if err != nil {
+			return false, nil
+		}
+		return owner.Equals(user), nil
+	}
+
+	listing, err := unwrap.Array(s.act.Call(s.marketHash, "getListing", token))
+	if err != nil || len(listing) != 3 {
+		return false, nil // билет не выставлен на продажу
+	}
+
+	sellerBytes, err := listing[0].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("listing seller: %w", err)
+	}
+	seller, err := util.Uint160DecodeBytesBE(sellerBytes)
+	if err != nil {
+		return false, fmt.Errorf("listing seller: %w", err)
+	}
+
+	if method == "delist" {
+		return seller.Equals(user), nil
+	}
+	return !seller.Equals(user), nil
+}
+
+// checkNotaryRequestOffer checks that the offer call can succeed, otherwise fallback
+// transaction is sent: offer can't be made for own ticket or twice, only the ticket
+// owner (or its seller on market) can accept an offer that hasn't expired, only
+// existing offer can be withdrawn.
+func (s *Server) checkNotaryRequestOffer(nAct *notary.Actor, method string, args notaryRequestArgs) (bool, error) {
+	owner, err := unwrap.Uint160(s.act.Call(s.nftHash, "ownerOf", args.nftID))
+	if err != nil {
+		return false, nil // нет такого билета
+	}
+
+	offerer := args.sender
+	if method == "acceptOffer" {
+		offerer = args.offerer
+	}
+	offer, err := unwrap.Array(s.act.Call(s.marketHash, "getOffer", args.nftID, offerer))
+	hasOffer := err == nil && len(offer) == 4
+
+	switch method {
+	case "makeOffer":
+		return !hasOffer && !owner.Equals(args.sender), nil
+	case "withdrawOffer":
+		return hasOffer, nil
+	}
+
+	if !hasOffer {
+		return false, nil
+	}
+	expiration, err := offer[3].TryInteger()
+	if err != nil {
+		return false, fmt.Errorf("offer expiration: %w", err)
+	}
+	if expiration.Int64() < time.Now().UnixMilli() {
+		return false, nil // предложение истекло
+	}
+
+	if owner.Equals(s.marketHash) {
+		return s.checkNotaryRequestMarket(nAct, "delist", args.sender, args.nftID)
+	}
+	return owner.Equals(args.sender), nil
+}
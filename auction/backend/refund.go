@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// validateNotaryRequestFundRefundPool validates fundRefundPool(funder, collectionID, amount)
+// call of auction contract.
+func validateNotaryRequestFundRefundPool(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, int, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, 0, 0, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, 0, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 3 {
+		return util.Uint160{}, 0, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	// аргументы в обратном порядке: amount, collectionID, funder
+	amount, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not parse amount: %w", err)
+	}
+	if amount <= 0 {
+		return util.Uint160{}, 0, 0, fmt.Errorf("invalid amount: %d", amount)
+	}
+
+	collection, err := IntFromOpcode(args[1])
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not parse collection id: %w", err)
+	}
+
+	funder, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, 0, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return funder, int(collection), int(amount), nil
+}
+
+// validateNotaryRequestClaimRefund validates claimRefund(holder, token) call of auction contract.
+func validateNotaryRequestClaimRefund(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, nil, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 2 {
+		return util.Uint160{}, nil, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	holder, err := util.Uint160DecodeBytesBE(args[1].Param())
+	if err != nil {
+		return util.Uint160{}, nil, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return holder, args[0].Param(), nil
+}
+
+// checkNotaryRequestRefund checks that the refund pool of the collection can be
+// funded (the collection is cancelled) or the refund can be claimed by the sender
+// (the ticket is void and held by the sender, the refund isn't claimed yet and the
+// pool covers it), otherwise fallback transaction is sent.
+func (s *Server) checkNotaryRequestRefund(nAct *notary.Actor, method string, args notaryRequestArgs) (bool, error) {
+	if method == "fundRefundPool" {
+		cancelled, err := unwrap.Bool(s.act.Call(s.nftHash, "isCancelled", args.collection))
+		if err != nil {
+			return false, nil // нет такой серии
+		}
+		return cancelled, nil
+	}
+
+	if !s.isVoid(args.nftID) {
+		return false, nil
+	}
+	owner, err := unwrap.Uint160(s.act.Call(s.nftHash, "ownerOf", args.nftID))
+	if err != nil || !owner.Equals(args.sender) {
+		return false, nil
+	}
+	collection, err := unwrap.Int64(s.act.Call(s.nftHash, "collectionOf", args.nftID))
+	if err != nil {
+		return false, fmt.Errorf("call collectionOf: %w", err)
+	}
+
+	refund, err := unwrap.Array(s.act.Call(s.auctionHash, "getRefund", args.nftID))
+	if err != nil || len(refund) != 2 {
+		return false, fmt.Errorf("unexpected refund: %v", refund)
+	}
+	amount, err := refund[0].TryInteger()
+	if err != nil {
+		return false, fmt.Errorf("refund amount: %w", err)
+	}
+	claimed, err := refund[1].TryBool()
+	if err != nil {
+		return false, fmt.Errorf("refund claimed flag: %w", err)
+	}
+	pool, err := unwrap.Int64(s.act.Call(s.auctionHash, "getRefundPool", collection))
+	if err != nil {
+		return false, fmt.Errorf("call getRefundPool: %w", err)
+	}
+
+	return !claimed && amount.Sign() > 0 && amount.Int64() <= pool, nil
+}
+
+// isVoid returns true if the ticket belongs to a cancelled collection.
+func (s *Server) isVoid(token []byte) bool {
+	void, err := unwrap.Bool(s.act.Call(s.nftHash, "isVoid", token))
+	return err == nil && void
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/actor"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+// stateFinished is the index state of auctions that are over, other states are
+// the ones returned by getState of auction contract.
+const stateFinished = "finished"
+
+// metadataLimits are maximum lengths in bytes of strings accepted by auction
+// contract (see its getLimits).
+type metadataLimits struct {
+	title       int
+	description int
+	category    int
+	reason      int // dispute reason
+}
+
+func readLimits(act *actor.Actor, auctionHash util.Uint160) (metadataLimits, error) {
+	items, err := unwrap.ArrayOfBigInts(act.Call(auctionHash, "getLimits"))
+	if err != nil {
+		return metadataLimits{}, fmt.Errorf("call getLimits: %w", err)
+	}
+	if len(items) != 4 {
+		return metadataLimits{}, fmt.Errorf("unexpected limits: %v", items)
+	}
+	return metadataLimits{
+		title:       int(items[0].Int64()),
+		description: int(items[1].Int64()),
+		category:    int(items[2].Int64()),
+		reason:      int(items[3].Int64()),
+	}, nil
+}
+
+// auctionRecord is an auction started via backend, indexed for search by its metadata.
+type auctionRecord struct {
+	Tx          string   `json:"tx,omitempty"`
+	Organizer   string   `json:"organizer"`
+	Contract    string   `json:"contract"` // NEP-11 contract of the lot
+	Lot         []string `json:"lot"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	State       string   `json:"state"`
+}
+
+// auctionIndex keeps started auctions in memory, newest last. Auction contract
+// runs one auction at a time, so only the newest record can be not finished.
+type auctionIndex struct {
+	mu      sync.RWMutex
+	records []auctionRecord
+}
+
+// add appends the current auction, the previous one is finished by then.
+func (i *auctionIndex) add(rec auctionRecord) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.finishLocked()
+	i.records = append(i.records, rec)
+}
+
+// finish marks the current auction finished.
+func (i *auctionIndex) finish() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.finishLocked()
+}
+
+func (i *auctionIndex) finishLocked() {
+	if len(i.records) != 0 {
+		i.records[len(i.records)-1].State = stateFinished
+	}
+}
+
+// setState updates the state of the current auction with the one returned by
+// getState, "none" means it's finished.
+func (i *auctionIndex) setState(state string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.records) == 0 || i.records[len(i.records)-1].State == stateFinished {
+		return
+	}
+	if state == "none" {
+		state = stateFinished
+	}
+	i.records[len(i.records)-1].State = state
+}
+
+// search returns auctions whose title or description contains the query (case
+// insensitive) and whose category and state are the given ones, empty query,
+// category and state match everything. Newest auctions go first.
+func (i *auctionIndex) search(query string, category string, state string) []auctionRecord {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	res := []auctionRecord{}
+	for k := len(i.records) - 1; k >= 0; k-- {
+		rec := i.records[k]
+		if category != "" && !strings.EqualFold(rec.Category, category) {
+			continue
+		}
+		if state != "" && rec.State != state {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(rec.Title), query) &&
+			!strings.Contains(strings.ToLower(rec.Description), query) {
+			continue
+		}
+		res = append(res, rec)
+	}
+	return res
+}
+
+// indexCurrentAuction adds the current auction of auction contract to the index,
+// tx is the start transaction (zero if it's unknown, e.g. on backend start).
+func (s *Server) indexCurrentAuction(tx util.Uint256, organizer util.Uint160) error {
+	state, err := unwrap.UTF8String(s.act.Call(s.auctionHash, "getState"))
+	if err != nil {
+		return fmt.Errorf("call getState: %w", err)
+	}
+
+	meta, err := unwrap.Array(s.act.Call(s.auctionHash, "getMetadata"))
+	if err != nil {
+		return fmt.Errorf("call getMetadata: %w", err)
+	}
+	if len(meta) != 3 {
+		return fmt.Errorf("unexpected metadata: %v", meta)
+	}
+	fields := make([]string, len(meta))
+	for k, item := range meta {
+		b, err := item.TryBytes()
+		if err != nil {
+			return fmt.Errorf("metadata field %d: %w", k, err)
+		}
+		fields[k] = string(b)
+	}
+
+	lotItems, err := unwrap.Array(s.act.Call(s.auctionHash, "showLot"))
+	if err != nil {
+		return fmt.Errorf("call showLot: %w", err)
+	}
+	lot := make([]string, 0, len(lotItems))
+	for _, item := range lotItems {
+		b, err := item.TryBytes()
+		if err != nil {
+			return fmt.Errorf("lot ticket: %w", err)
+		}
+		lot = append(lot, hex.EncodeToString(b))
+	}
+
+	contract, err := unwrap.Uint160(s.act.Call(s.auctionHash, "getNftContract"))
+	if err != nil {
+		return fmt.Errorf("call getNftContract: %w", err)
+	}
+
+	rec := auctionRecord{
+		Organizer:   address.Uint160ToString(organizer),
+		Contract:    contract.StringLE(),
+		Lot:         lot,
+		Title:       fields[0],
+		Description: fields[1],
+		Category:    fields[2],
+		State:       state,
+	}
+	if !tx.Equals(util.Uint256{}) {
+		rec.Tx = tx.StringLE()
+	}
+	s.index.add(rec)
+
+	return nil
+}
+
+// handleAuctions returns indexed auctions as json. Query parameters: q (text in
+// title or description), category and state, all optional. The index is kept in
+// memory only: after restart it contains the current auction of the contract
+// (without start transaction) and the auctions started since then, earlier
+// finished auctions aren't returned.
+func (s *Server) handleAuctions(w http.ResponseWriter, r *http.Request) {
+	s.log.Info("auctions request", zap.String("url", r.URL.String()))
+
+	// аукцион мог завершиться или начаться по расписанию без участия backend
+	state, err := unwrap.UTF8String(s.act.Call(s.auctionHash, "getState"))
+	if err != nil {
+		s.log.Error("call getState", zap.Error(err))
+	} else {
+		s.index.setState(state)
+	}
+
+	query := r.URL.Query()
+	data, err := json.Marshal(s.index.search(query.Get("q"), query.Get("category"), query.Get("state")))
+	if err != nil {
+		s.log.Error("marshal auctions", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(data); err != nil {
+		s.log.Error("write response error", zap.Error(err))
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// validateNotaryRequestDispute validates dispute(winner, settlementID, reason) call of auction contract.
+func validateNotaryRequestDispute(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, 0, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 3 {
+		return util.Uint160{}, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	// аргументы в обратном порядке: reason, settlementID, winner
+	if len(args[0].Param()) > s.limits.reason {
+		return util.Uint160{}, 0, fmt.Errorf("dispute reason is too long: %d", len(args[0].Param()))
+	}
+
+	id, err := IntFromOpcode(args[1])
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not parse settlement id: %w", err)
+	}
+
+	winner, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return winner, int(id), nil
+}
+
+// validateNotaryRequestClaim validates claim(settlementID) call of auction contract.
+func validateNotaryRequestClaim(req *payload.P2PNotaryRequest, s *Server) (int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return 0, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+
+	if len(args) != 1 {
+		return 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	id, err := IntFromOpcode(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("could not parse settlement id: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// checkNotaryRequestSettlement checks that the settlement can be disputed by the
+// sender (dispute) or claimed (claim) now, otherwise fallback transaction is sent.
+// The settlement with the void lot can be claimed at any time, it's refunded then.
+func (s *Server) checkNotaryRequestSettlement(nAct *notary.Actor, operation string, sender util.Uint160, id int) (bool, error) {
+	item, err := unwrap.Item(s.act.Call(s.auctionHash, "getSettlement", id))
+	if err != nil {
+		return false, nil // нет такого расчета
+	}
+	fields, ok := item.Value().([]stackitem.Item)
+	if !ok || len(fields) != 11 {
+		return false, fmt.Errorf("unexpected settlement: %v", item)
+	}
+
+	// Settlement{ID, Organizer, Winner, Lot, Price, Token, Fee, Deadline, Disputed, Reason, Nft}
+	winnerBytes, err := fields[2].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("settlement winner: %w", err)
+	}
+	winner, err := util.Uint160DecodeBytesBE(winnerBytes)
+	if err != nil {
+		return false, fmt.Errorf("settlement winner: %w", err)
+	}
+	deadline, err := fields[7].TryInteger()
+	if err != nil {
+		return false, fmt.Errorf("settlement deadline: %w", err)
+	}
+	disputed, err := fields[8].TryBool()
+	if err != nil {
+		return false, fmt.Errorf("settlement dispute flag: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	switch operation {
+	case "dispute":
+		return winner.Equals(sender) && !disputed && now <= deadline.Int64(), nil
+	case "claim":
+		void, err := s.settlementIsVoid(fields)
+		if err != nil {
+			return false, err
+		}
+		return void || !disputed && now > deadline.Int64(), nil
+	}
+
+	return false, nil
+}
+
+// settlementIsVoid returns true if the lot of nft.auc tickets of the settlement
+// contains a void ticket, like lotIsVoid of auction contract.
+func (s *Server) settlementIsVoid(fields []stackitem.Item) (bool, error) {
+	nftBytes, err := fields[10].TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("settlement nft contract: %w", err)
+	}
+	nftHash, err := util.Uint160DecodeBytesBE(nftBytes)
+	if err != nil {
+		return false, fmt.Errorf("settlement nft contract: %w", err)
+	}
+	if !nftHash.Equals(s.nftHash) {
+		return false, nil
+	}
+
+	lot, ok := fields[3].Value().([]stackitem.Item)
+	if !ok {
+		return false, fmt.Errorf("unexpected settlement lot: %v", fields[3])
+	}
+	for _, item := range lot {
+		token, err := item.TryBytes()
+		if err != nil {
+			return false, fmt.Errorf("settlement lot: %w", err)
+		}
+		if s.isVoid(token) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
-	"encoding/binary"
 	"fmt"
+	"slices"
 
 	"github.com/nspcc-dev/neo-go/pkg/neorpc/result"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/nspcc-dev/neo-go/pkg/vm/vmstate"
 	"go.uber.org/zap"
 )
 
@@ -23,15 +27,55 @@ func (s *Server) proceedMainTxStartAuction(nAct *notary.Actor, notaryEvent *resu
 		zap.String("main", mainHash.String()), zap.String("fb", fallbackHash.String()),
 		zap.Uint32("vub", vub))
 
-	_, err = nAct.Wait(mainHash, fallbackHash, vub, err) // ждем, пока какая-нибудь tx будет принята
+	res, err := nAct.Wait(mainHash, fallbackHash, vub, err) // ждем, пока какая-нибудь tx будет принята
 	if err != nil {
 		return fmt.Errorf("wait: %w", err)
 	}
 
+	// метаданные есть только у аукционов, запущенных start, другой запущенный аукцион
+	// только завершает предыдущий в индексе
+	if res.Container.Equals(mainHash) && res.VMState == vmstate.Halt {
+		switch currentOperation {
+		case "start":
+			organizer := notaryEvent.NotaryRequest.MainTransaction.Signers[1].Account
+			if err = s.indexCurrentAuction(mainHash, organizer); err != nil {
+				s.log.Error("index auction", zap.Error(err))
+			}
+		case "startMultiUnit", "startRaffle":
+			s.index.finish()
+		}
+	}
+
 	return nil
 }
 
-func validateNotaryRequestStartAuction(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, []byte, int, error) {
+// Number of arguments of auction contract methods sponsored by backend.
+const (
+	startArgs            = 16
+	startMultiUnitArgs   = 4
+	startRaffleArgs      = 4
+	allowlistArgs        = 2
+	requireAttributeArgs = 3
+)
+
+// checkArgsCount checks that the contract call in the main transaction has
+// exactly count arguments.
+func checkArgsCount(req *payload.P2PNotaryRequest, count int) error {
+	got, err := callArgsCount(req)
+	if err != nil {
+		return err
+	}
+	if got != count {
+		return fmt.Errorf("invalid argument count: %d, expected %d", got, count)
+	}
+	return nil
+}
+
+// validateNotaryRequestStartAuction validates start and startMultiUnit calls of auction
+// contract. withTerms is true for start, it takes the terms of the auction (allowlist,
+// bond, gate, FrostfsID attribute, payment token, start time, metadata and NFT
+// contract) besides the arguments of startMultiUnit.
+func validateNotaryRequestStartAuction(req *payload.P2PNotaryRequest, s *Server, withTerms bool) (util.Uint160, [][]byte, int, error) {
 
 	args, contractHash, err := validateNotaryRequestPreProcessing(req)
 	if err != nil {
@@ -44,22 +88,297 @@ func validateNotaryRequestStartAuction(req *payload.P2PNotaryRequest, s *Server)
 		return util.Uint160{}, nil, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
 	}
 
-	if len(args) != 3 { // start принимает ровно 3 аргумента
+	count := startMultiUnitArgs
+	if withTerms {
+		count = startArgs
+	}
+	if err = checkArgsCount(req, count); err != nil {
+		return util.Uint160{}, nil, 0, err
+	}
+
+	// start(auctionOwner, lot, initBet, paid, allowlist, bond, gate, gateCollection, attrName, attrValue, paymentToken, startTime,
+	// title, description, category, nftContract) и
+	// startMultiUnit(auctionOwner, lot, reservePrice, paid), аргументы лежат в обратном порядке,
+	// lot и allowlist - массивы (PUSHDATA элементов, количество и PACK или NEWARRAY0 для пустого массива),
+	// gate, paymentToken и nftContract - хэш контракта или PUSHNULL, attrName и attrValue - строки (пустые, если атрибут не требуется)
+	if withTerms {
+		if len(args) < startArgs { // каждый аргумент - хотя бы один опкод
+			return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+		}
+
+		if args[0].Code() != opcode.PUSHNULL {
+			if _, err := util.Uint160DecodeBytesBE(args[0].Param()); err != nil {
+				return util.Uint160{}, nil, 0, fmt.Errorf("invalid NFT contract hash: %w", err)
+			}
+		}
+		args = args[1:]
+
+		// метаданные аукциона - строки с теми же ограничениями длины, что и в контракте
+		category, description, title := args[0].Param(), args[1].Param(), args[2].Param()
+		if len(title) > s.limits.title || len(description) > s.limits.description || len(category) > s.limits.category {
+			return util.Uint160{}, nil, 0, fmt.Errorf("auction metadata is too long")
+		}
+		args = args[3:]
+
+		startTime, err := IntFromOpcode(args[0])
+		if err != nil {
+			return util.Uint160{}, nil, 0, fmt.Errorf("could not parse start time: %w", err)
+		}
+		if startTime < 0 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("invalid start time: %d", startTime)
+		}
+		args = args[1:]
+
+		if args[0].Code() != opcode.PUSHNULL {
+			if _, err := util.Uint160DecodeBytesBE(args[0].Param()); err != nil {
+				return util.Uint160{}, nil, 0, fmt.Errorf("invalid payment token hash: %w", err)
+			}
+		}
+		args = args[1:]
+
+		attrValue, attrName := args[0].Param(), args[1].Param()
+		if len(attrName) != 0 && len(attrValue) == 0 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("empty value of required attribute %s", attrName)
+		}
+		args = args[2:]
+
+		gateCollection, err := IntFromOpcode(args[0])
+		if err != nil {
+			return util.Uint160{}, nil, 0, fmt.Errorf("could not parse gate collection: %w", err)
+		}
+		if gateCollection < 0 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("invalid gate collection: %d", gateCollection)
+		}
+		if args[1].Code() != opcode.PUSHNULL {
+			if _, err := util.Uint160DecodeBytesBE(args[1].Param()); err != nil {
+				return util.Uint160{}, nil, 0, fmt.Errorf("invalid gate contract hash: %w", err)
+			}
+		}
+		args = args[2:]
+
+		bond, err := IntFromOpcode(args[0])
+		if err != nil {
+			return util.Uint160{}, nil, 0, fmt.Errorf("could not parse bond: %w", err)
+		}
+		if bond < 0 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("invalid bond: %d", bond)
+		}
+		args = args[1:]
+
+		// элементы массива - PUSHDATA, поэтому первый PACK или NEWARRAY0 завершает allowlist
+		end := slices.IndexFunc(args, func(op Op) bool {
+			return op.Code() == opcode.PACK || op.Code() == opcode.NEWARRAY0
+		})
+		if end < 0 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("no allowlist")
+		}
+		allowlist, allowlistOps, err := BytesArrayFromOpcodes(args[:end+1])
+		if err != nil {
+			return util.Uint160{}, nil, 0, fmt.Errorf("could not parse allowlist: %w", err)
+		}
+		if allowlistOps != end+1 {
+			return util.Uint160{}, nil, 0, fmt.Errorf("invalid allowlist length: %d", len(allowlist))
+		}
+		for _, bidder := range allowlist {
+			if _, err := util.Uint160DecodeBytesBE(bidder); err != nil {
+				return util.Uint160{}, nil, 0, fmt.Errorf("invalid allowlist address: %w", err)
+			}
+		}
+		args = args[allowlistOps:]
+	}
+
+	_, boolOps, err := BoolFromOpcodes(args)
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse paid flag: %w", err)
+	}
+	args = args[boolOps:]
+
+	if len(args) < 4 { // initBet, хотя бы PUSHN и PACK массива и auctionOwner
 		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
 	}
 
-	nftIdBytes := args[1].Param()
+	lot, lotOps, err := BytesArrayFromOpcodes(args[:len(args)-1])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse lot: %w", err)
+	}
+	if len(lot) == 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("lot is empty")
+	}
+
+	if len(args) != lotOps+2 { // кроме флага и лота start принимает ровно 2 аргумента
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
 
-	initBet := int(binary.LittleEndian.Uint16(args[0].Param()))
+	initBet64, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse initial bet: %w", err)
+	}
+	initBet := int(initBet64)
 
-	sh, err := util.Uint160DecodeBytesBE(args[2].Param())
+	sh, err := util.Uint160DecodeBytesBE(args[len(args)-1].Param())
 	if err != nil {
 		return util.Uint160{}, nil, 0, fmt.Errorf("could not decode script hash: %w", err)
 	}
 
-	return sh, nftIdBytes, initBet, err
+	return sh, lot, initBet, err
+}
+
+// checkNotaryRequestStartAuction checks that a new auction can be started: the
+// contract runs one auction at a time.
+func (s *Server) checkNotaryRequestStartAuction(nAct *notary.Actor) (bool, error) {
+	state, err := unwrap.UTF8String(s.act.Call(s.auctionHash, "getState"))
+	if err != nil {
+		return false, fmt.Errorf("call getState: %w", err)
+	}
+
+	return state == "none", nil
+}
+
+// checkNotaryRequestOrganizer checks that the sender of addToAllowlist,
+// removeFromAllowlist or requireAttribute is the organizer of the current auction.
+func (s *Server) checkNotaryRequestOrganizer(nAct *notary.Actor, sender util.Uint160) (bool, error) {
+	item, err := unwrap.Item(s.act.Call(s.auctionHash, "getOrganizer"))
+	if err != nil {
+		return false, fmt.Errorf("call getOrganizer: %w", err)
+	}
+	if _, ok := item.(stackitem.Null); ok {
+		return false, nil // аукцион не идет, отправляем fallback
+	}
+	data, err := item.TryBytes()
+	if err != nil {
+		return false, fmt.Errorf("organizer: %w", err)
+	}
+	organizer, err := util.Uint160DecodeBytesBE(data)
+	if err != nil {
+		return false, fmt.Errorf("organizer: %w", err)
+	}
+
+	return organizer.Equals(sender), nil
 }
 
-func (s *Server) checkNotaryRequestStartAuction(nAct *notary.Actor, organizer util.Uint160, lotId []byte, initBet int) (bool, error) {
-	return true, nil
+// validateNotaryRequestStartRaffle validates startRaffle(auctionOwner, lot, entryPrice, duration)
+// call of auction contract.
+func validateNotaryRequestStartRaffle(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, [][]byte, int, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, nil, 0, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, nil, 0, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+	if err = checkArgsCount(req, startRaffleArgs); err != nil {
+		return util.Uint160{}, nil, 0, err
+	}
+
+	if len(args) < 5 { // duration, entryPrice, хотя бы PUSHN и PACK массива и auctionOwner
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	duration, err := IntFromOpcode(args[0])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse duration: %w", err)
+	}
+	if duration <= 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid duration: %d", duration)
+	}
+
+	entryPrice, err := IntFromOpcode(args[1])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse entry price: %w", err)
+	}
+	if entryPrice < 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid entry price: %d", entryPrice)
+	}
+
+	lot, lotOps, err := BytesArrayFromOpcodes(args[:len(args)-1])
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not parse lot: %w", err)
+	}
+	if len(lot) == 0 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("lot is empty")
+	}
+
+	if len(args) != lotOps+3 {
+		return util.Uint160{}, nil, 0, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[len(args)-1].Param())
+	if err != nil {
+		return util.Uint160{}, nil, 0, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, lot, int(entryPrice), nil
+}
+
+// validateNotaryRequestAllowlist validates addToAllowlist(organizer, bidders) and
+// removeFromAllowlist(organizer, bidders) calls of auction contract.
+func validateNotaryRequestAllowlist(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+	if err = checkArgsCount(req, allowlistArgs); err != nil {
+		return util.Uint160{}, err
+	}
+
+	if len(args) < 2 {
+		return util.Uint160{}, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	bidders, biddersOps, err := BytesArrayFromOpcodes(args[:len(args)-1])
+	if err != nil {
+		return util.Uint160{}, fmt.Errorf("could not parse bidders: %w", err)
+	}
+	if len(args) != biddersOps+1 {
+		return util.Uint160{}, fmt.Errorf("invalid param length: %d", len(args))
+	}
+	for _, bidder := range bidders {
+		if _, err := util.Uint160DecodeBytesBE(bidder); err != nil {
+			return util.Uint160{}, fmt.Errorf("invalid bidder address: %w", err)
+		}
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[len(args)-1].Param())
+	if err != nil {
+		return util.Uint160{}, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, nil
+}
+
+// validateNotaryRequestRequireAttribute validates requireAttribute(organizer, attrName, attrValue)
+// call of auction contract.
+func validateNotaryRequestRequireAttribute(req *payload.P2PNotaryRequest, s *Server) (util.Uint160, error) {
+	args, contractHash, err := validateNotaryRequestPreProcessing(req)
+	if err != nil {
+		return util.Uint160{}, err
+	}
+
+	if !contractHash.Equals(s.auctionHash) {
+		return util.Uint160{}, fmt.Errorf("unexpected contract hash: %s", contractHash)
+	}
+	if err = checkArgsCount(req, requireAttributeArgs); err != nil {
+		return util.Uint160{}, err
+	}
+
+	if len(args) != 3 {
+		return util.Uint160{}, fmt.Errorf("invalid param length: %d", len(args))
+	}
+
+	attrValue, attrName := args[0].Param(), args[1].Param()
+	if len(attrName) != 0 && len(attrValue) == 0 {
+		return util.Uint160{}, fmt.Errorf("empty value of required attribute %s", attrName)
+	}
+
+	sh, err := util.Uint160DecodeBytesBE(args[2].Param())
+	if err != nil {
+		return util.Uint160{}, fmt.Errorf("could not decode script hash: %w", err)
+	}
+
+	return sh, nil
 }
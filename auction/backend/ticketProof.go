@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
+	"go.uber.org/zap"
+)
+
+// ticketProofDomain is the prefix of every signed ticket proof, it keeps the
+// holder's key from signing arbitrary venue-chosen bytes.
+const ticketProofDomain = "web3-auction-ticket-proof:"
+
+// maxTicketNonceLength is the maximum length of the venue nonce in bytes.
+const maxTicketNonceLength = 64
+
+// ticketProofResponse is returned by /verify-ticket to the venue.
+type ticketProofResponse struct {
+	Valid bool   `json:"valid"`
+	Owner string `json:"owner,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// handleVerifyTicket checks that the holder of the key from the query signed
+// the venue nonce for the token and that this key owns the token on-chain.
+// Query parameters: nonce (hex), key (hex compressed public key), signature (hex).
+func (s *Server) handleVerifyTicket(w http.ResponseWriter, r *http.Request) {
+	s.log.Info("verify ticket request", zap.String("url", r.URL.String()))
+
+	tokenIDStr := r.PathValue("tokenID")
+	tokenID, err := hex.DecodeString(tokenIDStr)
+	if err != nil {
+		s.log.Error("invalid token ID", zap.String("tokenID", tokenIDStr), zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	nonce, err := hex.DecodeString(query.Get("nonce"))
+	if err != nil || len(nonce) == 0 || len(nonce) > maxTicketNonceLength {
+		s.log.Error("invalid nonce", zap.String("nonce", query.Get("nonce")), zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	pub, err := keys.NewPublicKeyFromString(query.Get("key"))
+	if err != nil {
+		s.log.Error("invalid public key", zap.String("key", query.Get("key")), zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		s.log.Error("invalid signature", zap.String("signature", query.Get("signature")), zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var resp ticketProofResponse
+	owner, err := s.verifyTicketProof(tokenID, nonce, pub, signature)
+	if err != nil {
+		s.log.Info("ticket proof rejected", zap.String("tokenID", tokenIDStr), zap.Error(err))
+		resp.Error = err.Error()
+	} else {
+		resp.Valid = true
+		resp.Owner = owner
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		s.log.Error("marshal ticket proof response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if resp.Valid {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusForbidden)
+	}
+	if _, err = w.Write(data); err != nil {
+		s.log.Error("write response error", zap.Error(err))
+	}
+}
+
+// verifyTicketProof checks the signature over ticketProofMessage and returns
+// the address of the token owner if it matches the signing key.
+func (s *Server) verifyTicketProof(tokenID, nonce []byte, pub *keys.PublicKey, signature []byte) (string, error) {
+	msgHash := hash.Sha256(ticketProofMessage(uint32(s.act.GetNetwork()), tokenID, nonce))
+	if !pub.Verify(signature, msgHash.BytesBE()) {
+		return "", errors.New("invalid signature")
+	}
+
+	owner, err := unwrap.Uint160(s.act.Call(s.nftHash, "ownerOf", tokenID))
+	if err != nil {
+		return "", fmt.Errorf("call ownerOf: %w", err)
+	}
+
+	if !owner.Equals(pub.GetScriptHash()) {
+		return "", errors.New("signer is not the owner of the ticket")
+	}
+
+	return address.Uint160ToString(owner), nil
+}
+
+// ticketProofMessage is the payload signed by the client: domain tag, network
+// magic (LE), token ID length (uint32 LE), token ID and the venue nonce, so a
+// proof can't be replayed for another ticket or network. client builds the same.
+func ticketProofMessage(magic uint32, tokenID, nonce []byte) []byte {
+	msg := make([]byte, 0, len(ticketProofDomain)+8+len(tokenID)+len(nonce))
+	msg = append(msg, ticketProofDomain...)
+	msg = binary.LittleEndian.AppendUint32(msg, magic)
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(tokenID)))
+	msg = append(msg, tokenID...)
+	return append(msg, nonce...)
+}
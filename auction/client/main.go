@@ -6,30 +6,36 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
-	"git.frostfs.info/TrueCloudLab/hrw"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/base58"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/actor"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/gas"
+	"github.com/nspcc-dev/neo-go/pkg/rpcclient/nep17"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/notary"
 	"github.com/nspcc-dev/neo-go/pkg/rpcclient/unwrap"
 	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
 	"github.com/nspcc-dev/neo-go/pkg/vm/vmstate"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/spf13/viper"
@@ -43,9 +49,13 @@ const (
 	cfgPassword      = "password"
 	cfgNnsContract   = "nns_contract"
 	cfgBackendURL    = "backend_url"
+	cfgGatewayURL    = "frostfs_gateway_url"
 )
 
-var listOfTickets []string
+// signerContracts are auction, nft and market contracts resolved from NNS and GAS
+// (paid auction bets and market purchases). User witness in notary requests is
+// valid only in them and in the payment token of the current auction (CustomContracts scope).
+var signerContracts []util.Uint160
 
 func main() {
 	ctx, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM) // если пользователь нажмет ctrl+C, то завершим выполнение
@@ -83,15 +93,9 @@ func main() {
 	die(err)
 	auctionContractHash, err := GetNnsResolve("auc.auc", nnsContractHash, viper.GetString(cfgRPCEndpoint))
 	die(err)
-
-	numbers := make([]int, 100) // создание списка имен пока еще свободных nft
-	for i := 1; i <= 100; i++ {
-		numbers[i-1] = i
-	}
-	listOfTickets = make([]string, len(numbers))
-	for i, num := range numbers {
-		listOfTickets[i] = strconv.Itoa(num)
-	}
+	marketContractHash, err := GetNnsResolve("market.auc", nnsContractHash, viper.GetString(cfgRPCEndpoint))
+	die(err)
+	signerContracts = []util.Uint160{auctionContractHash, nftContractHash, marketContractHash, gas.Hash}
 
 	go ListenNotifications(ctx, rpcEndpointWc, auctionContractHash.StringLE())
 
@@ -123,15 +127,19 @@ func main() {
 		case <-ctx.Done():
 			die(ctx.Err())
 		case input := <-in:
-			args := strings.Fields(input)
+			args := splitArgs(input)
 
 			commandName := args[0]
 
 			die(claimNotaryDeposit(acc)) // запрос НД
 
 			switch commandName {
-			case "startAuction":
-				nftId := args[1] // lot
+			case "startAuction", "startMultiUnit":
+				if len(args) < 3 {
+					fmt.Printf("usage: %s <tokenID>[,<tokenID>...] <initBet> [paid] [allow=<address>,...] [bond=<amount>] [gate=<contractHash>[:<collectionID>]] [attr=<name>:<value>] [token=<contractHash>] [at=<YYYY-MM-DDTHH:MM>] [title=<title>] [desc=<description>] [category=<tag>] [nft=<contractHash>]\n", commandName)
+					continue
+				}
+				nftIds := strings.Split(args[1], ",") // lot: id билета или несколько id через запятую
 
 				initBetStr := args[2] // initBet
 				initBet, err := strconv.Atoi(initBetStr)
@@ -139,9 +147,145 @@ func main() {
 					fmt.Printf("Error converting bet number to integer: %v\n", err)
 					return
 				}
-				die(makeNotaryRequestStartAuction(backendKey, acc, rpcCli, auctionContractHash, nftId, initBet)) // создание НЗ (оборачивает main tx, которая состоит в вызове метода контракта)
+				// paid - ставки переводятся в GAS на контракт auction, allow - ставить могут только
+				// перечисленные адреса, bond - залог в GAS, который нужно внести до первой ставки,
+				// gate - ставить могут только владельцы NFT контракта (и серии, если она указана),
+				// attr - ставить могут только субъекты FrostfsID с атрибутом name=value (например, kyc:passed),
+				// token - NEP-17 токен, в котором переводятся ставки и залог (по умолчанию GAS),
+				// at - время начала запланированного аукциона (по местному времени), ставки принимаются с него,
+				// title, desc, category - метаданные для поиска (значения с пробелами берутся в кавычки: title="Summer concert"),
+				// nft - NEP-11 контракт лота, если это не билеты nft.auc (например, домены nns), контракт должен быть разрешен в auction
+				var (
+					paid                bool
+					allowlist           = []any{}
+					bond                int
+					gate                any
+					gateCollection      int
+					attrName, attrValue string
+					paymentToken        any
+					startTime           int64
+					title, description  string
+					category            string
+					nftContract         any
+				)
+				for _, opt := range args[3:] {
+					switch {
+					case opt == "paid":
+						paid = true
+					case strings.HasPrefix(opt, "allow="):
+						for _, addr := range strings.Split(strings.TrimPrefix(opt, "allow="), ",") {
+							bidder, err := address.StringToUint160(addr)
+							if err != nil {
+								fmt.Printf("Invalid allowlist address %s: %v\n", addr, err)
+								continue
+							}
+							allowlist = append(allowlist, bidder)
+						}
+					case strings.HasPrefix(opt, "bond="):
+						bond, err = strconv.Atoi(strings.TrimPrefix(opt, "bond="))
+						if err != nil {
+							fmt.Printf("Error converting bond to integer: %v\n", err)
+						}
+					case strings.HasPrefix(opt, "gate="):
+						hashStr, collectionStr, withCollection := strings.Cut(strings.TrimPrefix(opt, "gate="), ":")
+						gateHash, err := util.Uint160DecodeStringLE(hashStr)
+						if err != nil {
+							fmt.Printf("Invalid gate contract hash %s: %v\n", hashStr, err)
+							continue
+						}
+						gate = gateHash
+						if withCollection {
+							gateCollection, err = strconv.Atoi(collectionStr)
+							if err != nil {
+								fmt.Printf("Error converting gate collection to integer: %v\n", err)
+							}
+						}
+					case strings.HasPrefix(opt, "attr="):
+						name, value, ok := strings.Cut(strings.TrimPrefix(opt, "attr="), ":")
+						if !ok || name == "" || value == "" {
+							fmt.Printf("Invalid attribute %s, expected attr=<name>:<value>\n", opt)
+							continue
+						}
+						attrName, attrValue = name, value
+					case strings.HasPrefix(opt, "token="):
+						tokenHash, err := util.Uint160DecodeStringLE(strings.TrimPrefix(opt, "token="))
+						if err != nil {
+							fmt.Printf("Invalid payment token hash %s: %v\n", opt, err)
+							continue
+						}
+						paymentToken = tokenHash
+					case strings.HasPrefix(opt, "at="):
+						at, err := time.ParseInLocation("2006-01-02T15:04", strings.TrimPrefix(opt, "at="), time.Local)
+						if err != nil {
+							fmt.Printf("Invalid start time %s: %v\n", opt, err)
+							continue
+						}
+						startTime = at.UnixMilli()
+					case strings.HasPrefix(opt, "title="):
+						title = strings.TrimPrefix(opt, "title=")
+					case strings.HasPrefix(opt, "desc="):
+						description = strings.TrimPrefix(opt, "desc=")
+					case strings.HasPrefix(opt, "category="):
+						category = strings.TrimPrefix(opt, "category=")
+					case strings.HasPrefix(opt, "nft="):
+						nftHash, err := util.Uint160DecodeStringLE(strings.TrimPrefix(opt, "nft="))
+						if err != nil {
+							fmt.Printf("Invalid NFT contract hash %s: %v\n", opt, err)
+							continue
+						}
+						nftContract = nftHash
+					}
+				}
+				if commandName == "startMultiUnit" {
+					// билеты лота продаются как одинаковые единицы, initBet - минимальная цена единицы
+					die(makeNotaryRequestStartAuction(backendKey, acc, rpcCli, auctionContractHash, "startMultiUnit", nftIds, initBet, paid))
+					continue
+				}
+				die(makeNotaryRequestStartAuction(backendKey, acc, rpcCli, auctionContractHash, "start", nftIds, initBet, paid, allowlist, bond, gate, gateCollection, attrName, attrValue, paymentToken, startTime, title, description, category, nftContract))
+			case "allow", "disallow":
+				if len(args) < 2 {
+					fmt.Printf("usage: %s <address>...\n", commandName)
+					continue
+				}
+				bidders := make([]any, 0, len(args)-1)
+				for _, addr := range args[1:] {
+					bidder, err := address.StringToUint160(addr)
+					if err != nil {
+						fmt.Printf("Invalid address %s: %v\n", addr, err)
+						continue
+					}
+					bidders = append(bidders, bidder)
+				}
+				method := "addToAllowlist"
+				if commandName == "disallow" {
+					method = "removeFromAllowlist"
+				}
+				die(makeNotaryRequestAuction(backendKey, acc, rpcCli, auctionContractHash, method, bidders))
+			case "requireAttr":
+				// без аргумента снимает требование атрибута
+				var attrName, attrValue string
+				if len(args) > 1 {
+					var ok bool
+					attrName, attrValue, ok = strings.Cut(args[1], ":")
+					if !ok || attrName == "" || attrValue == "" {
+						fmt.Println("usage: requireAttr [<name>:<value>]")
+						continue
+					}
+				}
+				die(makeNotaryRequestAuction(backendKey, acc, rpcCli, auctionContractHash, "requireAttribute", attrName, attrValue))
+			case "depositBond":
+				die(makeNotaryRequestAuction(backendKey, acc, rpcCli, auctionContractHash, "depositBond")) // создание НЗ (оборачивает main tx, которая состоит в вызове метода контракта)
 			case "getNFT":
-				die(makeNotaryRequestGetNft(backendKey, acc, rpcCli, nftContractHash))
+				if len(args) != 2 {
+					fmt.Println("usage: getNFT <collectionID>")
+					continue
+				}
+				collectionID, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting collection id to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestGetNft(backendKey, acc, rpcCli, nftContractHash, collectionID))
 			case "makeBet":
 				betStr := args[1]
 				bet, err := strconv.Atoi(betStr)
@@ -149,9 +293,186 @@ func main() {
 					fmt.Printf("Error converting bet number to integer: %v\n", err)
 					return
 				}
-				die(makeNotaryRequestMakeBet(backendKey, acc, rpcCli, auctionContractHash, bet))
+				die(makeNotaryRequestMakeBet(backendKey, acc, rpcCli, auctionContractHash, "makeBet", bet))
+			case "proxyBid":
+				// максимальная ставка: контракт сам поднимает видимую ставку до нее, когда ставят другие
+				if len(args) != 2 {
+					fmt.Println("usage: proxyBid <maxBet>")
+					continue
+				}
+				maxBid, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting bet number to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestMakeBet(backendKey, acc, rpcCli, auctionContractHash, "makeProxyBid", maxBid))
+			case "bidUnits":
+				if len(args) != 3 {
+					fmt.Println("usage: bidUnits <quantity> <price>")
+					continue
+				}
+				quantity, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting quantity to integer: %v\n", err)
+					continue
+				}
+				price, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Printf("Error converting price to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestBidUnits(backendKey, acc, rpcCli, auctionContractHash, quantity, price))
+			case "unitBids":
+				die(showUnitBids(rpcCli, acc, auctionContractHash))
+			case "startRaffle":
+				if len(args) != 4 {
+					fmt.Println("usage: startRaffle <tokenID>[,<tokenID>...] <entryPrice> <durationMinutes>")
+					continue
+				}
+				entryPrice, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Printf("Error converting entry price to integer: %v\n", err)
+					continue
+				}
+				minutes, err := strconv.Atoi(args[3])
+				if err != nil {
+					fmt.Printf("Error converting duration to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestStartRaffle(backendKey, acc, rpcCli, auctionContractHash, strings.Split(args[1], ","), entryPrice, minutes))
+			case "enterRaffle":
+				die(makeNotaryRequestEnterRaffle(backendKey, acc, rpcCli, auctionContractHash))
+			case "raffleStatus":
+				die(showRaffleStatus(rpcCli, acc, auctionContractHash))
+			case "auctionState":
+				die(showAuctionState(rpcCli, acc, auctionContractHash, nftContractHash))
 			case "finishAuction":
 				die(makeNotaryRequestFinishAuction(backendKey, acc, rpcCli, auctionContractHash))
+			case "dispute":
+				// победитель сообщает о проблеме с лотом до окончания окна расчета, решает арбитр
+				if len(args) < 3 {
+					fmt.Println("usage: dispute <settlementID> <reason>")
+					continue
+				}
+				id, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting settlement id to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestAuction(backendKey, acc, rpcCli, auctionContractHash, "dispute", id, strings.Join(args[2:], " ")))
+			case "claim":
+				if len(args) != 2 {
+					fmt.Println("usage: claim <settlementID>")
+					continue
+				}
+				id, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting settlement id to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestClaim(backendKey, acc, rpcCli, auctionContractHash, id))
+			case "settlements":
+				die(showSettlements(rpcCli, acc, auctionContractHash))
+			case "fundRefundPool":
+				// организатор отмененного мероприятия пополняет фонд возвратов серии в GAS
+				if len(args) != 3 {
+					fmt.Println("usage: fundRefundPool <collectionID> <amount>")
+					continue
+				}
+				collectionID, err := strconv.Atoi(args[1])
+				if err != nil {
+					fmt.Printf("Error converting collection id to integer: %v\n", err)
+					continue
+				}
+				amount, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Printf("Error converting amount to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestAuction(backendKey, acc, rpcCli, auctionContractHash, "fundRefundPool", collectionID, amount))
+			case "claimRefund":
+				if len(args) != 2 {
+					fmt.Println("usage: claimRefund <tokenID>")
+					continue
+				}
+				die(makeNotaryRequestMarket(backendKey, acc, rpcCli, auctionContractHash, "claimRefund", args[1]))
+			case "refundInfo":
+				if len(args) != 2 {
+					fmt.Println("usage: refundInfo <tokenID>")
+					continue
+				}
+				die(showRefund(rpcCli, acc, auctionContractHash, nftContractHash, args[1]))
+			case "list":
+				if len(args) != 3 {
+					fmt.Println("usage: list <tokenID> <price>")
+					continue
+				}
+				price, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Printf("Error converting price to integer: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestMarket(backendKey, acc, rpcCli, marketContractHash, "list", args[1], price))
+			case "delist", "buy", "withdrawOffer":
+				if len(args) != 2 {
+					fmt.Printf("usage: %s <tokenID>\n", commandName)
+					continue
+				}
+				die(makeNotaryRequestMarket(backendKey, acc, rpcCli, marketContractHash, commandName, args[1]))
+			case "listings":
+				die(showListings(rpcCli, acc, marketContractHash))
+			case "offer":
+				if len(args) != 4 {
+					fmt.Println("usage: offer <tokenID> <price> <durationMinutes>")
+					continue
+				}
+				price, err := strconv.Atoi(args[2])
+				if err != nil {
+					fmt.Printf("Error converting price to integer: %v\n", err)
+					continue
+				}
+				minutes, err := strconv.Atoi(args[3])
+				if err != nil {
+					fmt.Printf("Error converting duration to integer: %v\n", err)
+					continue
+				}
+				duration := (time.Duration(minutes) * time.Minute).Milliseconds()
+				die(makeNotaryRequestMarket(backendKey, acc, rpcCli, marketContractHash, "makeOffer", args[1], price, duration))
+			case "acceptOffer":
+				if len(args) != 3 {
+					fmt.Println("usage: acceptOffer <tokenID> <offererAddress>")
+					continue
+				}
+				offerer, err := address.StringToUint160(args[2])
+				if err != nil {
+					fmt.Printf("Invalid offerer address: %v\n", err)
+					continue
+				}
+				die(makeNotaryRequestMarket(backendKey, acc, rpcCli, marketContractHash, "acceptOffer", args[1], offerer))
+			case "offers":
+				// offers <tokenID> - предложения по билету, offers - свои предложения
+				if len(args) > 1 {
+					tokenID, err := hex.DecodeString(args[1])
+					if err != nil {
+						fmt.Printf("Invalid token id: %v\n", err)
+						continue
+					}
+					die(showOffers(rpcCli, acc, marketContractHash, "offersOf", tokenID))
+				} else {
+					die(showOffers(rpcCli, acc, marketContractHash, "offersBy", acc.ScriptHash()))
+				}
+			case "ticketProof":
+				if len(args) != 3 {
+					fmt.Println("usage: ticketProof <tokenID> <nonce>")
+					continue
+				}
+				die(makeTicketProof(rpcCli, acc, args[1], args[2]))
+			case "verifyTicket":
+				if len(args) != 2 {
+					fmt.Println("usage: verifyTicket <tokenID>")
+					continue
+				}
+				die(verifyTicket(rpcCli, acc, nftContractHash, args[1]))
 			default:
 				fmt.Printf("Unknown commandName: %s\n", commandName)
 			}
@@ -159,6 +480,33 @@ func main() {
 	}
 }
 
+// splitArgs splits the command into arguments by spaces, the text in double quotes
+// (e.g. title="Summer concert") is kept in one argument without quotes.
+func splitArgs(input string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t'):
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+	return args
+}
+
 func GetNnsResolve(domainName string, nnsContractHash string, rpcEndpoint string) (util.Uint160, error) {
 
 	type StackItem struct {
@@ -269,7 +617,22 @@ func claimNotaryDeposit(acc *wallet.Account) error {
 
 	return nil
 }
+
+// makeNotaryRequestPreProcessing creates notary actor. User signs with CustomContracts scope
+// limited to auction and nft contracts (and payment tokens and NEP-11 contracts allowed
+// by auction admin), backend rejects requests with broader scopes.
 func makeNotaryRequestPreProcessing(acc *wallet.Account, backendKey *keys.PublicKey, rpcCli *rpcclient.Client) (*notary.Actor, error) {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return nil, err
+	}
+	allowedContracts := slices.Clone(signerContracts)
+	// ставки текущего аукциона переводятся в его токене оплаты; подпись дается только токенам
+	// из списка администратора auction, а не тому, что вернул getPaymentToken
+	allowedContracts = appendAuctionContracts(act, allowedContracts, "paymentTokens")
+	// лот другого NEP-11 контракта переводится на auction при старте, поэтому нужна подпись для этого контракта
+	allowedContracts = appendAuctionContracts(act, allowedContracts, "supportedContracts")
+
 	coSigners := []actor.SignerAccount{
 		{
 			Signer: transaction.Signer{ // первый подписант - backend, который будет платить за tx, когда она примется (потому что платит первый подписант). Мы не знаем его  SK, поэтому ставим PK
@@ -280,8 +643,9 @@ func makeNotaryRequestPreProcessing(acc *wallet.Account, backendKey *keys.Public
 		},
 		{
 			Signer: transaction.Signer{
-				Account: acc.ScriptHash(), // следующий подписант - client, данная программа, она знает свой SK, поэтому ставит его
-				Scopes:  transaction.Global,
+				Account:          acc.ScriptHash(), // следующий подписант - client, данная программа, она знает свой SK, поэтому ставит его
+				Scopes:           transaction.CustomContracts,
+				AllowedContracts: allowedContracts,
 			},
 			Account: acc,
 		},
@@ -315,19 +679,14 @@ func makeNotaryRequestPostProcessing(tx *transaction.Transaction, nAct *notary.A
 	return res, err
 }
 
-func makeNotaryRequestGetNft(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160) error {
-	nftName, err := getFreeTicket(rpcCli, acc, contractHash) // находит свободную гифку
-	if err != nil {
-		return fmt.Errorf("get free ticket: %w", err)
-	}
-
+func makeNotaryRequestGetNft(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, collectionID int) error {
 	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
 	if err != nil {
 		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
 	}
 
-	tx, err := nAct.MakeTunedCall(contractHash, "mint", nil, nil, acc.ScriptHash(), nftName) // tx = вызов метода mint на
-	// контракте nft - себе получаем json
+	tx, err := nAct.MakeTunedCall(contractHash, "mint", nil, nil, acc.ScriptHash(), collectionID) // tx = вызов метода mint на
+	// контракте nft - себе получаем следующий билет серии
 	if err != nil {
 		return err
 	}
@@ -345,22 +704,31 @@ func makeNotaryRequestGetNft(backendKey *keys.PublicKey, acc *wallet.Account, rp
 		return err
 	}
 
-	fmt.Println("new token id", hex.EncodeToString(tokenID))
+	fmt.Printf("new token id %s (%s)\n", hex.EncodeToString(tokenID), string(tokenID))
 
 	return nil
 }
 
-func makeNotaryRequestStartAuction(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractAuctionHash util.Uint160, nftId string, initBet int) error {
+// makeNotaryRequestStartAuction calls start (terms are allowlist, bond, gate and its collection,
+// required FrostfsID attribute, payment token, start time, metadata) or
+// startMultiUnit of auction contract.
+func makeNotaryRequestStartAuction(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractAuctionHash util.Uint160, method string, nftIds []string, initBet int, paid bool, terms ...any) error {
 	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
 	if err != nil {
 		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
 	}
 
-	nftIdBytes, err := hex.DecodeString(nftId)
-	if err != nil {
-		fmt.Printf("Invalid convertion nftId: %s", err)
+	lot := make([]any, 0, len(nftIds))
+	for _, nftId := range nftIds {
+		nftIdBytes, err := hex.DecodeString(nftId)
+		if err != nil {
+			fmt.Printf("Invalid convertion nftId %s: %s\n", nftId, err)
+			return nil
+		}
+		lot = append(lot, nftIdBytes)
 	}
-	tx, err := nAct.MakeTunedCall(contractAuctionHash, "start", nil, nil, acc.ScriptHash(), nftIdBytes, initBet) // tx = вызов метода start на
+	params := append([]any{acc.ScriptHash(), lot, initBet, paid}, terms...)
+	tx, err := nAct.MakeTunedCall(contractAuctionHash, method, nil, nil, params...) // tx = вызов метода start на
 	// контракте auction
 	if err != nil {
 		return err
@@ -374,16 +742,393 @@ func makeNotaryRequestStartAuction(backendKey *keys.PublicKey, acc *wallet.Accou
 	return nil
 }
 
-func makeNotaryRequestMakeBet(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, bet int) error {
+// appendAuctionContracts appends contract hashes returned by the iterator method of auction.
+func appendAuctionContracts(act *actor.Actor, contracts []util.Uint160, method string) []util.Uint160 {
+	items, err := unwrap.Array(act.CallAndExpandIterator(signerContracts[0], method, 100))
+	if err != nil {
+		return contracts
+	}
+	for _, item := range items {
+		b, err := item.TryBytes()
+		if err != nil {
+			continue
+		}
+		h, err := util.Uint160DecodeBytesBE(b)
+		if err == nil && !slices.Contains(contracts, h) {
+			contracts = append(contracts, h)
+		}
+	}
+	return contracts
+}
+
+// makeNotaryRequestAuction calls auction contract method with the user as the first
+// argument followed by extra arguments (depositBond, addToAllowlist, removeFromAllowlist,
+// requireAttribute).
+func makeNotaryRequestAuction(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, method string, extra ...any) error {
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
+	}
+
+	params := append([]any{acc.ScriptHash()}, extra...)
+	tx, err := nAct.MakeTunedCall(contractHash, method, nil, nil, params...)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction for %s: %w", method, err)
+	}
+
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
+
+	fmt.Printf("%s done\n", method)
+
+	return nil
+}
+
+// makeNotaryRequestMakeBet calls makeBet or makeProxyBid (bet is the maximum then) of auction contract.
+func makeNotaryRequestMakeBet(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, method string, bet int) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	token, err := getPaymentToken(act, contractHash)
+	if err != nil {
+		return err
+	}
+
+	maxBet, err := unwrap.Int64(act.Call(contractHash, "maxBet")) // ограничение цены перепродажи для текущего лота, -1 если его нет
+	if err != nil {
+		return fmt.Errorf("get max bet: %w", err)
+	}
+	if maxBet >= 0 && int64(bet) > maxBet {
+		fmt.Printf("Bet %s exceeds resale price cap %s for the current lot, it's not sent\n", token.format(int64(bet)), token.format(maxBet))
+		return nil
+	}
+
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
+	}
+
+	tx, err := nAct.MakeTunedCall(contractHash, method, nil, nil, acc.ScriptHash(), bet)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction for %s: %w", method, err)
+	}
+
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
+
+	return nil
+}
+
+// makeNotaryRequestStartRaffle starts the raffle for the tickets, registration is open for minutes.
+func makeNotaryRequestStartRaffle(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, nftIds []string, entryPrice int, minutes int) error {
+	lot := make([]any, 0, len(nftIds))
+	for _, nftId := range nftIds {
+		nftIdBytes, err := hex.DecodeString(nftId)
+		if err != nil {
+			fmt.Printf("Invalid convertion nftId %s: %s\n", nftId, err)
+			return nil
+		}
+		lot = append(lot, nftIdBytes)
+	}
+
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
+	}
+
+	duration := (time.Duration(minutes) * time.Minute).Milliseconds()
+	tx, err := nAct.MakeTunedCall(contractHash, "startRaffle", nil, nil, acc.ScriptHash(), lot, entryPrice, duration)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction for startRaffle: %w", err)
+	}
+
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
+
+	return nil
+}
+
+// makeNotaryRequestEnterRaffle registers the user in the current raffle.
+func makeNotaryRequestEnterRaffle(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160) error {
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
+	}
+
+	tx, err := nAct.MakeTunedCall(contractHash, "enterRaffle", nil, nil, acc.ScriptHash())
+	if err != nil {
+		return fmt.Errorf("failed to create transaction for enterRaffle: %w", err)
+	}
+
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
+
+	return nil
+}
+
+// showRaffleStatus prints the state of the current raffle and whether the user has entered it.
+// It's a test invocation, so no transaction is sent.
+func showRaffleStatus(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	status, err := unwrap.Array(act.Call(contractHash, "raffleStatus"))
+	if err != nil {
+		return fmt.Errorf("call raffleStatus: %w", err)
+	}
+	if len(status) != 4 {
+		return fmt.Errorf("unexpected raffle status: %v", status)
+	}
+
+	values := make([]int64, len(status))
+	for i, item := range status {
+		v, err := item.TryInteger()
+		if err != nil {
+			return err
+		}
+		values[i] = v.Int64()
+	}
+	tickets, entries, entryPrice, end := values[0], values[1], values[2], values[3]
+
+	if end == 0 {
+		fmt.Println("raffle is not running")
+		return nil
+	}
+
+	entered, err := unwrap.Bool(act.Call(contractHash, "isEntered", acc.ScriptHash()))
+	if err != nil {
+		return fmt.Errorf("call isEntered: %w", err)
+	}
+
+	token, err := getPaymentToken(act, contractHash)
+	if err != nil {
+		return err
+	}
+
+	state := "open"
+	endTime := time.UnixMilli(end)
+	if endTime.Before(time.Now()) {
+		state = "closed, waiting for finish"
+	}
+
+	fmt.Printf("raffle for %d ticket(s), entry price %s, entries %d, registration %s until %s, entered: %t\n",
+		tickets, token.format(entryPrice), entries, state, endTime.Format(time.DateTime), entered)
+
+	return nil
+}
+
+// makeNotaryRequestBidUnits bids for quantity units of multi-unit auction at the price per unit.
+func makeNotaryRequestBidUnits(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, quantity int, price int) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	token, err := getPaymentToken(act, contractHash)
+	if err != nil {
+		return err
+	}
+
+	maxBet, err := unwrap.Int64(act.Call(contractHash, "maxBet")) // для multi-unit аукциона ограничение цены единицы
+	if err != nil {
+		return fmt.Errorf("get max bet: %w", err)
+	}
+	if maxBet >= 0 && int64(price) > maxBet {
+		fmt.Printf("Price %s exceeds resale price cap %s for the current lot, it's not sent\n", token.format(int64(price)), token.format(maxBet))
+		return nil
+	}
+
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
+	}
+
+	tx, err := nAct.MakeTunedCall(contractHash, "bidUnits", nil, nil, acc.ScriptHash(), quantity, price)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction for bidUnits: %w", err)
+	}
+
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
+
+	return nil
+}
+
+// showUnitBids prints bids of the current multi-unit auction.
+func showUnitBids(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	items, err := unwrap.Array(act.CallAndExpandIterator(contractHash, "unitBids", 100))
+	if err != nil {
+		return fmt.Errorf("call unitBids: %w", err)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("no bids")
+		return nil
+	}
+
+	token, err := getPaymentToken(act, contractHash)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		fields, ok := item.Value().([]stackitem.Item)
+		if !ok || len(fields) != 4 {
+			return fmt.Errorf("unexpected bid: %v", item)
+		}
+		bidderBytes, err := fields[0].TryBytes()
+		if err != nil {
+			return err
+		}
+		bidder, err := util.Uint160DecodeBytesBE(bidderBytes)
+		if err != nil {
+			return err
+		}
+		quantity, err := fields[1].TryInteger()
+		if err != nil {
+			return err
+		}
+		price, err := fields[2].TryInteger()
+		if err != nil {
+			return err
+		}
+		seq, err := fields[3].TryInteger()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("#%s %s units at %s, bidder %s\n", seq, quantity, token.format(price.Int64()), address.Uint160ToString(bidder))
+	}
+
+	return nil
+}
+
+// showAuctionState prints whether the auction is running or scheduled and when it
+// starts, NEP-11 contract of its lot if it isn't nft.auc, and its title, category and description.
+func showAuctionState(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160, nftHash util.Uint160) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
 
+	state, err := unwrap.UTF8String(act.Call(contractHash, "getState"))
+	if err != nil {
+		return fmt.Errorf("call getState: %w", err)
+	}
+	if state == "none" {
+		fmt.Println("auction state: none")
+		return nil
+	}
+	if state == "scheduled" {
+		startTime, err := unwrap.Int64(act.Call(contractHash, "getStartTime"))
+		if err != nil {
+			return fmt.Errorf("call getStartTime: %w", err)
+		}
+		fmt.Printf("auction state: scheduled, starts at %s\n", time.UnixMilli(startTime).Format(time.DateTime))
+	} else if state == "void" {
+		fmt.Println("auction state: void, the event is cancelled, anyone can finish the auction to refund bets")
+	} else {
+		fmt.Printf("auction state: %s\n", state)
+	}
+
+	lotContract, err := unwrap.Uint160(act.Call(contractHash, "getNftContract"))
+	if err != nil {
+		return fmt.Errorf("call getNftContract: %w", err)
+	}
+	if !lotContract.Equals(nftHash) {
+		fmt.Printf("lot contract: %s\n", lotContract.StringLE())
+	}
+
+	meta, err := unwrap.Array(act.Call(contractHash, "getMetadata"))
+	if err != nil {
+		return fmt.Errorf("call getMetadata: %w", err)
+	}
+	if len(meta) != 3 {
+		return fmt.Errorf("unexpected metadata: %v", meta)
+	}
+	fields := make([]string, len(meta))
+	for i, item := range meta {
+		b, err := item.TryBytes()
+		if err != nil {
+			return err
+		}
+		fields[i] = string(b)
+	}
+	if fields[0] != "" || fields[2] != "" {
+		fmt.Printf("title: %s, category: %s\n", fields[0], fields[2])
+	}
+	if fields[1] != "" {
+		fmt.Printf("description: %s\n", fields[1])
+	}
+
+	return nil
+}
+
+// paymentToken is NEP-17 token bets of the current auction are paid in.
+type paymentToken struct {
+	symbol   string
+	decimals int
+}
+
+// getPaymentToken returns symbol and decimals of the payment token of the current auction.
+func getPaymentToken(act *actor.Actor, contractHash util.Uint160) (paymentToken, error) {
+	hash, err := unwrap.Uint160(act.Call(contractHash, "getPaymentToken"))
+	if err != nil {
+		return paymentToken{}, fmt.Errorf("call getPaymentToken: %w", err)
+	}
+
+	return readPaymentToken(act, hash)
+}
+
+// readPaymentToken returns symbol and decimals of the NEP-17 token.
+func readPaymentToken(act *actor.Actor, hash util.Uint160) (paymentToken, error) {
+	reader := nep17.NewReader(act, hash)
+	symbol, err := reader.Symbol()
+	if err != nil {
+		return paymentToken{}, fmt.Errorf("payment token symbol: %w", err)
+	}
+	decimals, err := reader.Decimals()
+	if err != nil {
+		return paymentToken{}, fmt.Errorf("payment token decimals: %w", err)
+	}
+
+	return paymentToken{symbol: symbol, decimals: decimals}, nil
+}
+
+// format returns the amount in token units, e.g. 1.5 GAS for 150000000.
+func (t paymentToken) format(amount int64) string {
+	return fixedn.ToString(big.NewInt(amount), t.decimals) + " " + t.symbol
+}
+
+// makeNotaryRequestClaim completes the settlement after its dispute window.
+func makeNotaryRequestClaim(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, id int) error {
 	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
 	if err != nil {
 		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
 	}
 
-	tx, err := nAct.MakeTunedCall(contractHash, "makeBet", nil, nil, acc.ScriptHash(), bet)
+	tx, err := nAct.MakeTunedCall(contractHash, "claim", nil, nil, id)
 	if err != nil {
-		return fmt.Errorf("failed to create transaction for makeBet: %w", err)
+		return fmt.Errorf("failed to create transaction for claim: %w", err)
 	}
 
 	_, err = makeNotaryRequestPostProcessing(tx, nAct)
@@ -391,6 +1136,151 @@ func makeNotaryRequestMakeBet(backendKey *keys.PublicKey, acc *wallet.Account, r
 		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
 	}
 
+	fmt.Printf("settlement %d claimed\n", id)
+
+	return nil
+}
+
+// showSettlements prints settlements of finished paid auctions that wait for
+// the end of the dispute window or for the arbiter.
+func showSettlements(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	items, err := unwrap.Array(act.CallAndExpandIterator(contractHash, "settlements", 100))
+	if err != nil {
+		return fmt.Errorf("call settlements: %w", err)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("no settlements")
+		return nil
+	}
+
+	for _, item := range items {
+		// Settlement{ID, Organizer, Winner, Lot, Price, Token, Fee, Deadline, Disputed, Reason, Nft}
+		fields, ok := item.Value().([]stackitem.Item)
+		if !ok || len(fields) != 11 {
+			return fmt.Errorf("unexpected settlement: %v", item)
+		}
+		id, err := fields[0].TryInteger()
+		if err != nil {
+			return err
+		}
+		winnerBytes, err := fields[2].TryBytes()
+		if err != nil {
+			return err
+		}
+		winner, err := util.Uint160DecodeBytesBE(winnerBytes)
+		if err != nil {
+			return err
+		}
+		price, err := fields[4].TryInteger()
+		if err != nil {
+			return err
+		}
+		tokenBytes, err := fields[5].TryBytes()
+		if err != nil {
+			return err
+		}
+		tokenHash, err := util.Uint160DecodeBytesBE(tokenBytes)
+		if err != nil {
+			return err
+		}
+		token, err := readPaymentToken(act, tokenHash)
+		if err != nil {
+			return err
+		}
+		deadline, err := fields[7].TryInteger()
+		if err != nil {
+			return err
+		}
+		disputed, err := fields[8].TryBool()
+		if err != nil {
+			return err
+		}
+
+		state := "can be disputed until " + time.UnixMilli(deadline.Int64()).Format(time.DateTime)
+		if disputed {
+			reason, _ := fields[9].TryBytes()
+			state = "disputed: " + string(reason)
+		} else if time.UnixMilli(deadline.Int64()).Before(time.Now()) {
+			state = "ready to claim"
+		}
+		fmt.Printf("#%s price %s, winner %s, %s\n", id, token.format(price.Int64()), address.Uint160ToString(winner), state)
+	}
+
+	return nil
+}
+
+// showRefund prints whether the ticket is void, its last sale price, the refund
+// for it and the refund pool of its collection.
+func showRefund(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160, nftHash util.Uint160, tokenIDStr string) error {
+	tokenID, err := hex.DecodeString(tokenIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
+
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	void, err := unwrap.Bool(act.Call(nftHash, "isVoid", tokenID))
+	if err != nil {
+		return fmt.Errorf("call isVoid: %w", err)
+	}
+	if !void {
+		fmt.Println("ticket is not void, no refund")
+		return nil
+	}
+
+	gasToken, err := readPaymentToken(act, gas.Hash)
+	if err != nil {
+		return err
+	}
+	lastSale, err := unwrap.Int64(act.Call(contractHash, "getLastSalePrice", tokenID))
+	if err != nil {
+		return fmt.Errorf("call getLastSalePrice: %w", err)
+	}
+	refund, err := unwrap.Array(act.Call(contractHash, "getRefund", tokenID))
+	if err != nil {
+		return fmt.Errorf("call getRefund: %w", err)
+	}
+	if len(refund) != 2 {
+		return fmt.Errorf("unexpected refund: %v", refund)
+	}
+	amount, err := refund[0].TryInteger()
+	if err != nil {
+		return err
+	}
+	claimed, err := refund[1].TryBool()
+	if err != nil {
+		return err
+	}
+	collection, err := unwrap.Int64(act.Call(nftHash, "collectionOf", tokenID))
+	if err != nil {
+		return fmt.Errorf("call collectionOf: %w", err)
+	}
+	pool, err := unwrap.Int64(act.Call(contractHash, "getRefundPool", collection))
+	if err != nil {
+		return fmt.Errorf("call getRefundPool: %w", err)
+	}
+
+	if lastSale > 0 {
+		fmt.Printf("ticket is void, last sale price: %s\n", gasToken.format(lastSale))
+	} else {
+		fmt.Println("ticket is void, no sales in auction archive, nothing to refund")
+	}
+	if claimed {
+		fmt.Printf("refund %s is already claimed\n", gasToken.format(amount.Int64()))
+	} else {
+		fmt.Printf("refund: %s\n", gasToken.format(amount.Int64()))
+	}
+	fmt.Printf("refund pool of collection %d: %s\n", collection, gasToken.format(pool))
+
 	return nil
 }
 
@@ -426,43 +1316,250 @@ func makeNotaryRequestFinishAuction(backendKey *keys.PublicKey, acc *wallet.Acco
 	return nil
 }
 
-func getFreeTicket(cli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160) (string, error) {
-	// пробегает по списку гифок, определяет свободна или нет, дергая ownerOf. Найдя первую свободную, возвращает
+// makeNotaryRequestMarket calls market contract method (list, delist, buy or offer methods)
+// with the user as seller/buyer/offerer, the token and optional extra arguments (price for list,
+// price and duration for makeOffer, offerer for acceptOffer).
+func makeNotaryRequestMarket(backendKey *keys.PublicKey, acc *wallet.Account, rpcCli *rpcclient.Client, contractHash util.Uint160, method string, tokenIDStr string, extra ...any) error {
+	tokenID, err := hex.DecodeString(tokenIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
 
-	indexes := make([]uint64, len(listOfTickets))
-	for i := range indexes {
-		indexes[i] = uint64(i)
+	nAct, err := makeNotaryRequestPreProcessing(acc, backendKey, rpcCli)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPreProcessing: %w", err)
 	}
 
-	act, err := actor.NewSimple(cli, acc)
+	params := append([]any{acc.ScriptHash(), tokenID}, extra...)
+	tx, err := nAct.MakeTunedCall(contractHash, method, nil, nil, params...)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to create transaction for %s: %w", method, err)
 	}
 
-	h := hrw.Hash(acc.ScriptHash().BytesBE()) // сортировка опциональна, может быть какая-то другая логика поиска нужной гифки (ex ML), это просто
-	// как пример того, в каком порядке можно обходить список всех возможных гифок в поиске свободной
-	// если каждый клиент пойдет по порядку, все начнут с начала, а свободны только последние 2, то они все пройдут весь список - очень неэффективно, пусть
-	// идут с разных концов, используем рандеву-хэширование
-	hrw.Sort(indexes, h)
+	_, err = makeNotaryRequestPostProcessing(tx, nAct)
+	if err != nil {
+		return fmt.Errorf("makeNotaryRequestPostProcessing: %w", err)
+	}
 
-	var ticket string
-	for _, index := range indexes {
-		ticket = listOfTickets[index]
+	fmt.Printf("%s %s done\n", method, tokenIDStr)
 
-		hash := sha256.New()
-		hash.Write([]byte(ticket))
-		tokenID := hash.Sum(nil)
+	return nil
+}
 
-		if _, err := unwrap.Uint160(act.Call(contractHash, "ownerOf", tokenID)); err != nil {
-			break
+// showListings prints tickets on sale in market contract.
+func showListings(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	items, err := unwrap.Array(act.CallAndExpandIterator(contractHash, "listings", 100))
+	if err != nil {
+		return fmt.Errorf("call listings: %w", err)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("no tickets on sale")
+		return nil
+	}
+
+	for _, item := range items {
+		fields, ok := item.Value().([]stackitem.Item)
+		if !ok || len(fields) != 3 {
+			return fmt.Errorf("unexpected listing: %v", item)
+		}
+		sellerBytes, err := fields[0].TryBytes()
+		if err != nil {
+			return err
 		}
+		seller, err := util.Uint160DecodeBytesBE(sellerBytes)
+		if err != nil {
+			return err
+		}
+		token, err := fields[1].TryBytes()
+		if err != nil {
+			return err
+		}
+		price, err := fields[2].TryInteger()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ticket %s, price %s, seller %s\n", hex.EncodeToString(token), price, address.Uint160ToString(seller))
 	}
 
-	if ticket == "" {
-		return "", errors.New("all tickets are taken") // не осталось свободных токенов
+	return nil
+}
+
+// showOffers prints offers returned by market contract method (offersOf by ticket or
+// offersBy by offerer).
+func showOffers(rpcCli *rpcclient.Client, acc *wallet.Account, contractHash util.Uint160, method string, param any) error {
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
 	}
 
-	return ticket, nil
+	items, err := unwrap.Array(act.CallAndExpandIterator(contractHash, method, 100, param))
+	if err != nil {
+		return fmt.Errorf("call %s: %w", method, err)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("no offers")
+		return nil
+	}
+
+	for _, item := range items {
+		fields, ok := item.Value().([]stackitem.Item)
+		if !ok || len(fields) != 4 {
+			return fmt.Errorf("unexpected offer: %v", item)
+		}
+		offererBytes, err := fields[0].TryBytes()
+		if err != nil {
+			return err
+		}
+		offerer, err := util.Uint160DecodeBytesBE(offererBytes)
+		if err != nil {
+			return err
+		}
+		token, err := fields[1].TryBytes()
+		if err != nil {
+			return err
+		}
+		price, err := fields[2].TryInteger()
+		if err != nil {
+			return err
+		}
+		expiration, err := fields[3].TryInteger()
+		if err != nil {
+			return err
+		}
+
+		status := "active"
+		expiresAt := time.UnixMilli(expiration.Int64())
+		if expiresAt.Before(time.Now()) {
+			status = "expired"
+		}
+
+		fmt.Printf("ticket %s, price %s, offerer %s, expires %s (%s)\n", hex.EncodeToString(token), price,
+			address.Uint160ToString(offerer), expiresAt.Format(time.DateTime), status)
+	}
+
+	return nil
+}
+
+// ticketProofDomain is the prefix of every signed ticket proof, backend checks
+// proofs with the same message format.
+const ticketProofDomain = "web3-auction-ticket-proof:"
+
+// maxTicketNonceLength is the maximum length of the venue nonce in bytes.
+const maxTicketNonceLength = 64
+
+// makeTicketProof signs the nonce given by the venue together with the token ID
+// and prints the link the venue uses to check the proof on the backend.
+func makeTicketProof(rpcCli *rpcclient.Client, acc *wallet.Account, tokenIDStr string, nonceStr string) error {
+	tokenID, err := hex.DecodeString(tokenIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
+	nonce, err := hex.DecodeString(nonceStr)
+	if err != nil {
+		return fmt.Errorf("invalid nonce: %w", err)
+	}
+	if len(nonce) == 0 || len(nonce) > maxTicketNonceLength {
+		return fmt.Errorf("nonce must be 1 to %d bytes", maxTicketNonceLength)
+	}
+	version, err := rpcCli.GetVersion()
+	if err != nil {
+		return fmt.Errorf("get version: %w", err)
+	}
+
+	// домен, magic сети, длина id токена, id токена и nonce - тот же формат проверяет backend
+	msg := []byte(ticketProofDomain)
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(version.Protocol.Network))
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(tokenID)))
+	msg = append(msg, tokenID...)
+	msg = append(msg, nonce...)
+	signature := acc.PrivateKey().Sign(msg)
+	pubKey := acc.PrivateKey().PublicKey().StringCompressed()
+
+	fmt.Printf("ticket proof: token %s, key %s, signature %s\n", tokenIDStr, pubKey, hex.EncodeToString(signature))
+	fmt.Printf("verify: %s/verify-ticket/%s?nonce=%s&key=%s&signature=%s\n", viper.GetString(cfgBackendURL),
+		tokenIDStr, nonceStr, pubKey, hex.EncodeToString(signature))
+
+	return nil
+}
+
+// verifyTicket downloads ticket object from FrostFS through the http gateway and
+// compares its sha256 with the hash stored in the nft.
+func verifyTicket(rpcCli *rpcclient.Client, acc *wallet.Account, nftHash util.Uint160, tokenIDStr string) error {
+	tokenID, err := hex.DecodeString(tokenIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
+
+	act, err := actor.NewSimple(rpcCli, acc)
+	if err != nil {
+		return err
+	}
+
+	props, err := unwrap.Map(act.Call(nftHash, "properties", tokenID))
+	if err != nil {
+		return fmt.Errorf("call properties: %w", err)
+	}
+
+	var (
+		objAddress   string
+		expectedHash []byte
+	)
+	for _, item := range props.Value().([]stackitem.MapElement) {
+		k, err := item.Key.TryBytes()
+		if err != nil {
+			return err
+		}
+		v, err := item.Value.TryBytes()
+		if err != nil {
+			return err
+		}
+
+		switch string(k) {
+		case "address":
+			objAddress = string(v)
+		case "hash":
+			expectedHash = v
+		}
+	}
+
+	if objAddress == "" || len(expectedHash) == 0 {
+		fmt.Printf("ticket %s has no frostfs object or content hash yet\n", tokenIDStr)
+		return nil
+	}
+
+	resp, err := http.Get(viper.GetString(cfgGatewayURL) + "/get/" + objAddress)
+	if err != nil {
+		return fmt.Errorf("get object %s: %w", objAddress, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get object %s: %s", objAddress, resp.Status)
+	}
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read object %s: %w", objAddress, err)
+	}
+
+	actualHash := sha256.Sum256(payload)
+	if !bytes.Equal(actualHash[:], expectedHash) {
+		fmt.Printf("ticket %s is NOT valid: object %s hash %s, expected %s\n", tokenIDStr, objAddress,
+			hex.EncodeToString(actualHash[:]), hex.EncodeToString(expectedHash))
+		return nil
+	}
+
+	fmt.Printf("ticket %s is valid: object %s matches hash %s\n", tokenIDStr, objAddress, hex.EncodeToString(expectedHash))
+
+	return nil
 }
 
 func die(err error) {
@@ -58,6 +58,10 @@ func ListenNotifications(ctx context.Context, url string, contractToListen strin
 				continue
 			}
 
+			if eventName, _ := firstParam["eventname"].(string); eventName != "info" { // текстовые сообщения аукциона, остальные события для других приложений
+				continue
+			}
+
 			state, ok := firstParam["state"].(map[string]interface{})
 			if !ok {
 				fmt.Println("Invalid state type")
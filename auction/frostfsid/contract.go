@@ -0,0 +1,79 @@
+package frostfsid
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/lib/address"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/management"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// Stub of FrostfsID contract with subject attributes only, it's used where real
+// FrostfsID isn't deployed to check attribute requirements of auction.
+
+// Prefixes used for contract data storage.
+const (
+	adminKey  = "a"
+	subjectKV = "k" // subject + attribute name -> value
+
+	nnsSelfDomain         = "frostfsid.frostfs"
+	nnsRecordType         = 16
+	nnsContractHashString = "NcCZaxnLkXvrd56DgpFSSBjhj2DqzH3jKP"
+)
+
+func _deploy(data interface{}, isUpdate bool) {
+	if isUpdate {
+		return
+	}
+
+	storage.Put(storage.GetContext(), adminKey, runtime.GetScriptContainer().Sender)
+
+	selfHash := runtime.GetExecutingScriptHash()
+	contract.Call(address.ToHash160(nnsContractHashString), "register", contract.All, nnsSelfDomain, address.ToHash160("NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP"), "owner_email@mail.ru", 100, 100, 31536000, 31536000)
+	currentNnsRecord := contract.Call(address.ToHash160(nnsContractHashString), "getRecords", contract.All, nnsSelfDomain, nnsRecordType)
+	if currentNnsRecord != nil {
+		contract.Call(address.ToHash160(nnsContractHashString), "deleteRecords", contract.All, nnsSelfDomain, nnsRecordType)
+	}
+	contract.Call(address.ToHash160(nnsContractHashString), "addRecord", contract.All, nnsSelfDomain, nnsRecordType, address.FromHash160(selfHash))
+}
+
+func Update(script []byte, manifest []byte, data any) {
+	management.UpdateWithData(script, manifest, data)
+}
+
+// SetSubjectKV sets the attribute of the subject. Only admin can call it.
+func SetSubjectKV(addr interop.Hash160, name string, value string) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	storage.Put(ctx, mkSubjectKVKey(addr, name), value)
+}
+
+// DeleteSubjectKV deletes the attribute of the subject. Only admin can call it.
+func DeleteSubjectKV(addr interop.Hash160, name string) {
+	ctx := storage.GetContext()
+	checkAdmin(ctx)
+
+	storage.Delete(ctx, mkSubjectKVKey(addr, name))
+}
+
+// GetSubjectKV returns the attribute of the subject, empty string if it's not set.
+func GetSubjectKV(addr interop.Hash160, name string) string {
+	data := storage.Get(storage.GetReadOnlyContext(), mkSubjectKVKey(addr, name))
+	if data == nil {
+		return ""
+	}
+	return data.(string)
+}
+
+func checkAdmin(ctx storage.Context) {
+	if !runtime.CheckWitness(storage.Get(ctx, adminKey).(interop.Hash160)) {
+		panic("not witnessed by admin")
+	}
+}
+
+func mkSubjectKVKey(addr interop.Hash160, name string) []byte {
+	res := append([]byte(subjectKV), addr...)
+	return append(res, []byte(name)...)
+}
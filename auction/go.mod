@@ -9,7 +9,6 @@ replace github.com/nspcc-dev/neo-go => git.frostfs.info/TrueCloudLab/neoneo-go v
 require (
 	git.frostfs.info/TrueCloudLab/frostfs-node v0.44.6
 	git.frostfs.info/TrueCloudLab/frostfs-sdk-go v0.0.0-20241226115718-82e48c8a634d
-	git.frostfs.info/TrueCloudLab/hrw v1.2.1
 	github.com/gorilla/websocket v1.5.3
 	github.com/nspcc-dev/neo-go v0.107.2
 	github.com/nspcc-dev/neo-go/pkg/interop v0.0.0-20241228090728-4d2b88dd9dbd
@@ -21,6 +20,7 @@ require (
 	git.frostfs.info/TrueCloudLab/frostfs-contract v0.21.0-rc.4 // indirect
 	git.frostfs.info/TrueCloudLab/frostfs-crypto v0.6.0 // indirect
 	git.frostfs.info/TrueCloudLab/frostfs-observability v0.0.0-20241112082307-f17779933e88 // indirect
+	git.frostfs.info/TrueCloudLab/hrw v1.2.1 // indirect
 	git.frostfs.info/TrueCloudLab/rfc6979 v0.4.0 // indirect
 	git.frostfs.info/TrueCloudLab/tzhash v1.8.0 // indirect
 	git.frostfs.info/TrueCloudLab/zapjournald v0.0.0-20240124114243-cb2e66427d02 // indirect
@@ -0,0 +1,394 @@
+package market
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/interop"
+	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
+	"github.com/nspcc-dev/neo-go/pkg/interop/iterator"
+	"github.com/nspcc-dev/neo-go/pkg/interop/lib/address"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/crypto"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/gas"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/management"
+	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
+	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
+	"github.com/nspcc-dev/neo-go/pkg/interop/storage"
+)
+
+// Prefixes used for contract data storage.
+const (
+	listingPrefix = "l" // token id -> listing
+	offerPrefix   = "o" // sha256(token id) + offerer -> offer
+	offererPrefix = "u" // offerer + token id -> offer
+
+	offerPaymentKey = "p" // set while MakeOffer transfers the offer price
+
+	nnsSelfDomain         = "market.auc"
+	nnsNftDomain          = "nft.auc"
+	nnsRecordType         = 16
+	nnsContractHashString = "NcCZaxnLkXvrd56DgpFSSBjhj2DqzH3jKP"
+)
+
+// Listing is a ticket put on sale for a fixed price in GAS. The ticket is kept
+// by the contract until it's bought or delisted.
+type Listing struct {
+	Seller interop.Hash160
+	Token  []byte
+	Price  int
+}
+
+// Offer is a time-limited offer to buy the ticket for a price in GAS. The price
+// is kept by the contract until the offer is accepted or withdrawn.
+type Offer struct {
+	Offerer    interop.Hash160
+	Token      []byte
+	Price      int
+	Expiration int // milliseconds, like runtime.GetTime
+}
+
+// RoyaltyRecipient is an item of NEP-24 royaltyInfo result of nft contract.
+type RoyaltyRecipient struct {
+	Address interop.Hash160
+	Amount  int
+}
+
+func _deploy(data interface{}, isUpdate bool) {
+	if isUpdate {
+		return
+	}
+
+	selfHash := runtime.GetExecutingScriptHash()
+	contract.Call(address.ToHash160(nnsContractHashString), "register", contract.All, nnsSelfDomain, address.ToHash160("NfgHwwTi3wHAS8aFAN243C5vGbkYDpqLHP"), "owner_email@mail.ru", 100, 100, 31536000, 31536000)
+	currentNnsRecord := contract.Call(address.ToHash160(nnsContractHashString), "getRecords", contract.All, nnsSelfDomain, nnsRecordType)
+	if currentNnsRecord != nil {
+		contract.Call(address.ToHash160(nnsContractHashString), "deleteRecords", contract.All, nnsSelfDomain, nnsRecordType)
+	}
+	contract.Call(address.ToHash160(nnsContractHashString), "addRecord", contract.All, nnsSelfDomain, nnsRecordType, address.FromHash160(selfHash))
+}
+
+func Update(script []byte, manifest []byte, data any) {
+	management.UpdateWithData(script, manifest, data)
+}
+
+// List puts the ticket on sale: it's transferred to the contract and the listing
+// is created in OnNEP11Payment.
+func List(seller interop.Hash160, token []byte, price int) {
+	if !runtime.CheckWitness(seller) {
+		panic("not witnessed by seller")
+	}
+	if price <= 0 {
+		panic("price must be positive")
+	}
+
+	nftHash := resolveNft()
+	owner := contract.Call(nftHash, "ownerOf", contract.ReadOnly, token).(interop.Hash160)
+	if !owner.Equals(seller) {
+		panic("you can't list this ticket because you're not its owner")
+	}
+
+	transferred := contract.Call(nftHash, "transfer", contract.All, runtime.GetExecutingScriptHash(), token, price).(bool)
+	if !transferred {
+		panic("failed to transfer the ticket to market")
+	}
+}
+
+// OnNEP11Payment receives tickets put on sale, data is the price.
+func OnNEP11Payment(from interop.Hash160, amount int, token []byte, data any) {
+	if !runtime.GetCallingScriptHash().Equals(resolveNft()) {
+		panic("only tickets are accepted")
+	}
+	if data == nil {
+		panic("no price is specified")
+	}
+	price := data.(int)
+	if price <= 0 {
+		panic("price must be positive")
+	}
+
+	checkNotVoid(runtime.GetCallingScriptHash(), token)
+
+	ctx := storage.GetContext()
+	if storage.Get(ctx, mkListingKey(token)) != nil {
+		panic("ticket is already listed")
+	}
+
+	setListing(ctx, Listing{
+		Seller: from,
+		Token:  token,
+		Price:  price,
+	})
+
+	runtime.Notify("Listed", token, from, price)
+}
+
+// Delist removes the ticket from sale and returns it to the seller.
+func Delist(seller interop.Hash160, token []byte) {
+	ctx := storage.GetContext()
+	listing := getListing(ctx, token)
+	if !listing.Seller.Equals(seller) || !runtime.CheckWitness(seller) {
+		panic("only seller can delist the ticket")
+	}
+
+	storage.Delete(ctx, mkListingKey(token))
+
+	transferred := contract.Call(resolveNft(), "transfer", contract.All, seller, token, nil).(bool)
+	if !transferred {
+		panic("failed to return the ticket")
+	}
+
+	runtime.Notify("Delisted", token, seller)
+}
+
+// Buy pays the listing price in GAS (royalty to its recipient, the rest to
+// the seller) and transfers the ticket to the buyer.
+func Buy(buyer interop.Hash160, token []byte) {
+	if !runtime.CheckWitness(buyer) {
+		panic("not witnessed by buyer")
+	}
+
+	ctx := storage.GetContext()
+	listing := getListing(ctx, token)
+	if listing.Seller.Equals(buyer) {
+		panic("seller cannot buy own ticket")
+	}
+
+	storage.Delete(ctx, mkListingKey(token))
+
+	nftHash := resolveNft()
+	checkNotVoid(nftHash, token)
+	payForTicket(nftHash, token, buyer, buyer, listing.Seller, listing.Price)
+
+	transferred := contract.Call(nftHash, "transfer", contract.All, buyer, token, nil).(bool)
+	if !transferred {
+		panic("failed to transfer the ticket")
+	}
+
+	runtime.Notify("Sold", token, listing.Seller, buyer, listing.Price)
+}
+
+// MakeOffer offers to buy the ticket for the price, the price is transferred
+// to the contract. The offer can be accepted during duration milliseconds.
+func MakeOffer(offerer interop.Hash160, token []byte, price int, duration int) {
+	if !runtime.CheckWitness(offerer) {
+		panic("not witnessed by offerer")
+	}
+	if price <= 0 {
+		panic("price must be positive")
+	}
+	if duration <= 0 {
+		panic("duration must be positive")
+	}
+
+	nftHash := resolveNft()
+	checkNotVoid(nftHash, token)
+	owner := contract.Call(nftHash, "ownerOf", contract.ReadOnly, token).(interop.Hash160)
+	if owner.Equals(offerer) {
+		panic("you already own this ticket")
+	}
+
+	ctx := storage.GetContext()
+	if storage.Get(ctx, mkOfferKey(token, offerer)) != nil {
+		panic("offer already exists, withdraw it first")
+	}
+
+	storage.Put(ctx, offerPaymentKey, true)
+	if !gas.Transfer(offerer, runtime.GetExecutingScriptHash(), price, token) {
+		panic("failed to transfer offer price")
+	}
+	storage.Delete(ctx, offerPaymentKey)
+
+	offer := Offer{
+		Offerer:    offerer,
+		Token:      token,
+		Price:      price,
+		Expiration: runtime.GetTime() + duration,
+	}
+	setOffer(ctx, offer)
+
+	runtime.Notify("OfferMade", token, offerer, price, offer.Expiration)
+}
+
+// AcceptOffer sells the ticket to the offerer for the offered price. The ticket
+// may be listed by the owner, then the listing is removed.
+func AcceptOffer(owner interop.Hash160, token []byte, offerer interop.Hash160) {
+	if !runtime.CheckWitness(owner) {
+		panic("not witnessed by owner")
+	}
+
+	ctx := storage.GetContext()
+	offer := getOffer(ctx, token, offerer)
+	if offer.Expiration < runtime.GetTime() {
+		panic("offer has expired")
+	}
+
+	nftHash := resolveNft()
+	checkNotVoid(nftHash, token)
+	self := runtime.GetExecutingScriptHash()
+	currentOwner := contract.Call(nftHash, "ownerOf", contract.ReadOnly, token).(interop.Hash160)
+	if currentOwner.Equals(self) {
+		listing := getListing(ctx, token)
+		if !listing.Seller.Equals(owner) {
+			panic("you're not the owner of this ticket")
+		}
+		storage.Delete(ctx, mkListingKey(token))
+	} else if !currentOwner.Equals(owner) {
+		panic("you're not the owner of this ticket")
+	}
+
+	deleteOffer(ctx, offer)
+
+	payForTicket(nftHash, token, self, offerer, owner, offer.Price)
+
+	transferred := contract.Call(nftHash, "transfer", contract.All, offerer, token, nil).(bool)
+	if !transferred {
+		panic("failed to transfer the ticket")
+	}
+
+	runtime.Notify("Sold", token, owner, offerer, offer.Price)
+}
+
+// WithdrawOffer removes the offer and returns its price to the offerer. Expired
+// offers are withdrawn the same way.
+func WithdrawOffer(offerer interop.Hash160, token []byte) {
+	if !runtime.CheckWitness(offerer) {
+		panic("not witnessed by offerer")
+	}
+
+	ctx := storage.GetContext()
+	offer := getOffer(ctx, token, offerer)
+	deleteOffer(ctx, offer)
+
+	if !gas.Transfer(runtime.GetExecutingScriptHash(), offerer, offer.Price, nil) {
+		panic("failed to return offer price")
+	}
+
+	runtime.Notify("OfferWithdrawn", token, offerer)
+}
+
+// GetOffer returns the offer of the offerer for the ticket.
+func GetOffer(token []byte, offerer interop.Hash160) Offer {
+	return getOffer(storage.GetReadOnlyContext(), token, offerer)
+}
+
+// OffersOf returns an iterator with all offers for the ticket.
+func OffersOf(token []byte) iterator.Iterator {
+	ctx := storage.GetReadOnlyContext()
+	key := append([]byte(offerPrefix), crypto.Sha256(token)...)
+	return storage.Find(ctx, key, storage.ValuesOnly|storage.DeserializeValues)
+}
+
+// OffersBy returns an iterator with all offers of the offerer.
+func OffersBy(offerer interop.Hash160) iterator.Iterator {
+	ctx := storage.GetReadOnlyContext()
+	key := append([]byte(offererPrefix), offerer...)
+	return storage.Find(ctx, key, storage.ValuesOnly|storage.DeserializeValues)
+}
+
+// OnNEP17Payment accepts GAS only while MakeOffer transfers the offer price,
+// stray transfers are rejected.
+func OnNEP17Payment(from interop.Hash160, amount int, data any) {
+	if !runtime.GetCallingScriptHash().Equals(gas.Hash) {
+		panic("only GAS is accepted")
+	}
+	if data == nil || storage.Get(storage.GetReadOnlyContext(), offerPaymentKey) == nil {
+		panic("GAS is accepted only for offers")
+	}
+}
+
+// GetListing returns the listing of the ticket.
+func GetListing(token []byte) Listing {
+	return getListing(storage.GetReadOnlyContext(), token)
+}
+
+// Listings returns an iterator with all current listings.
+func Listings() iterator.Iterator {
+	ctx := storage.GetReadOnlyContext()
+	return storage.Find(ctx, []byte(listingPrefix), storage.ValuesOnly|storage.DeserializeValues)
+}
+
+// payForTicket pays the price in GAS from the payer: NEP-24 royalty to its recipients,
+// the rest to the seller.
+func payForTicket(nftHash interop.Hash160, token []byte, payer interop.Hash160, buyer interop.Hash160, seller interop.Hash160, price int) {
+	royalty := 0
+	recipients := contract.Call(nftHash, "royaltyInfo", contract.ReadOnly, token, gas.Hash, price).([]RoyaltyRecipient)
+	for _, r := range recipients {
+		if r.Amount <= 0 {
+			continue
+		}
+		royalty += r.Amount
+		if royalty > price {
+			panic("royalty exceeds the price")
+		}
+		if !gas.Transfer(payer, r.Address, r.Amount, nil) {
+			panic("failed to pay royalty")
+		}
+		runtime.Notify("RoyaltiesTransferred", interop.Hash160(gas.Hash), r.Address, buyer, token, r.Amount)
+	}
+
+	if !gas.Transfer(payer, seller, price-royalty, nil) {
+		panic("failed to pay the seller")
+	}
+}
+
+// checkNotVoid panics if the ticket belongs to a cancelled event, such tickets
+// can only be delisted or refunded.
+func checkNotVoid(nftHash interop.Hash160, token []byte) {
+	if contract.Call(nftHash, "isVoid", contract.ReadOnly, token).(bool) {
+		panic("ticket is void, the event is cancelled")
+	}
+}
+
+func resolveNft() interop.Hash160 {
+	nftContractHashStringArray := contract.Call(address.ToHash160(nnsContractHashString), "resolve", contract.ReadOnly, nnsNftDomain, nnsRecordType).([]string)
+	return address.ToHash160(nftContractHashStringArray[0])
+}
+
+func getListing(ctx storage.Context, token []byte) Listing {
+	val := storage.Get(ctx, mkListingKey(token))
+	if val == nil {
+		panic("ticket is not listed")
+	}
+	return std.Deserialize(val.([]byte)).(Listing)
+}
+
+func setListing(ctx storage.Context, listing Listing) {
+	storage.Put(ctx, mkListingKey(listing.Token), std.Serialize(listing))
+}
+
+func getOffer(ctx storage.Context, token []byte, offerer interop.Hash160) Offer {
+	val := storage.Get(ctx, mkOfferKey(token, offerer))
+	if val == nil {
+		panic("offer not found")
+	}
+	return std.Deserialize(val.([]byte)).(Offer)
+}
+
+func setOffer(ctx storage.Context, offer Offer) {
+	val := std.Serialize(offer)
+	storage.Put(ctx, mkOfferKey(offer.Token, offer.Offerer), val)
+	storage.Put(ctx, mkOffererKey(offer.Offerer, offer.Token), val)
+}
+
+func deleteOffer(ctx storage.Context, offer Offer) {
+	storage.Delete(ctx, mkOfferKey(offer.Token, offer.Offerer))
+	storage.Delete(ctx, mkOffererKey(offer.Offerer, offer.Token))
+}
+
+// mkOfferKey creates DB key for the offer by concatenating offerPrefix, sha256 of
+// token ID (fixed length, so offers of one token can be found by prefix) and offerer.
+func mkOfferKey(token []byte, offerer interop.Hash160) []byte {
+	res := append([]byte(offerPrefix), crypto.Sha256(token)...)
+	return append(res, offerer...)
+}
+
+// mkOffererKey creates DB key for the offer in offerer's index by concatenating
+// offererPrefix, offerer and token ID.
+func mkOffererKey(offerer interop.Hash160, token []byte) []byte {
+	res := append([]byte(offererPrefix), offerer...)
+	return append(res, token...)
+}
+
+// mkListingKey creates DB key for the listing by concatenating listingPrefix
+// and token ID.
+func mkListingKey(token []byte) []byte {
+	res := []byte(listingPrefix)
+	return append(res, token...)
+}
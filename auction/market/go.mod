@@ -0,0 +1,7 @@
+module market
+
+go 1.22
+
+toolchain go1.22.10
+
+require github.com/nspcc-dev/neo-go/pkg/interop v0.0.0-20241212130705-ea0a6114d2d6
@@ -5,7 +5,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/interop/contract"
 	"github.com/nspcc-dev/neo-go/pkg/interop/iterator"
 	"github.com/nspcc-dev/neo-go/pkg/interop/lib/address"
-	"github.com/nspcc-dev/neo-go/pkg/interop/native/crypto"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/management"
 	"github.com/nspcc-dev/neo-go/pkg/interop/native/std"
 	"github.com/nspcc-dev/neo-go/pkg/interop/runtime"
@@ -17,9 +16,19 @@ const (
 	balancePrefix = "b"
 	accountPrefix = "a"
 	tokenPrefix   = "t"
+	stagePrefix   = "p" // ticket data prepared by the backend before mint
 
-	ownerKey       = 'o'
-	totalSupplyKey = 's'
+	collectionPrefix       = "c"
+	collectionTokensPrefix = "k"
+	approvalPrefix         = "e" // token -> account allowed to transfer it
+	operatorPrefix         = "f" // owner + operator -> operator may transfer all owner's tokens
+
+	ownerKey          = 'o'
+	totalSupplyKey    = 's'
+	lastCollectionKey = 'n'
+
+	tokenIDSeparator = "-"
+	maxRoyaltyRate   = 10000 // 100% in basis points
 
 	nnsSelfDomain         = "nft.auc"
 	nnsRecordType         = 16
@@ -27,10 +36,45 @@ const (
 )
 
 type NFTItem struct {
-	ID      []byte
-	Name    string
-	Owner   interop.Hash160
-	Address string
+	ID         []byte
+	Name       string
+	Owner      interop.Hash160
+	Address    string
+	Hash       []byte // sha256 of the ticket object stored in FrostFS
+	Collection int
+	Serial     int
+
+	// ticket metadata, filled by the backend from the ticket JSON
+	Description string
+	Image       string
+	EventName   string
+	Date        string
+	Row         string
+	Seat        string
+	Category    string
+	FaceValue   int // original ticket price in GAS fractions, resale price is capped relative to it
+}
+
+// Collection is a series of tickets of one event. Token IDs of its tickets are
+// "<collection id>-<serial>", serials go from 1 to MaxSupply.
+type Collection struct {
+	ID          int
+	Name        string
+	Description string
+	Source      string // url of ticket JSONs, serial is appended to it
+	MaxSupply   int
+	Minted      int
+
+	RoyaltyRecipient interop.Hash160
+	RoyaltyRate      int // in basis points of the sale price
+
+	Cancelled bool // event is cancelled, tickets of the collection are void
+}
+
+// RoyaltyRecipient is an item of NEP-24 royaltyInfo result.
+type RoyaltyRecipient struct {
+	Address interop.Hash160
+	Amount  int
 }
 
 func _deploy(data interface{}, isUpdate bool) {
@@ -99,10 +143,36 @@ func Properties(token []byte) map[string]string {
 	nft := getNFT(ctx, token)
 
 	result := map[string]string{
-		"id":      string(nft.ID),
-		"owner":   ownerAddress(nft.Owner),
-		"name":    nft.Name,
-		"address": nft.Address,
+		"id":          string(nft.ID),
+		"owner":       ownerAddress(nft.Owner),
+		"name":        nft.Name,
+		"description": nft.Description,
+		"image":       nft.Image,
+		"address":     nft.Address,
+		"hash":        string(nft.Hash),
+		"collection":  std.Itoa10(nft.Collection),
+		"serial":      std.Itoa10(nft.Serial),
+	}
+	if nft.EventName != "" {
+		result["eventName"] = nft.EventName
+	}
+	if nft.Date != "" {
+		result["date"] = nft.Date
+	}
+	if nft.Row != "" {
+		result["row"] = nft.Row
+	}
+	if nft.Seat != "" {
+		result["seat"] = nft.Seat
+	}
+	if nft.Category != "" {
+		result["category"] = nft.Category
+	}
+	if nft.FaceValue != 0 {
+		result["faceValue"] = std.Itoa10(nft.FaceValue)
+	}
+	if nft.Collection != 0 && getCollection(ctx, nft.Collection).Cancelled {
+		result["void"] = "true"
 	}
 	return result
 }
@@ -127,6 +197,22 @@ func TokensList() []string {
 	return keys
 }
 
+// BalanceOfCollection returns the number of tokens of the collection held by the
+// specified address.
+func BalanceOfCollection(holder interop.Hash160, collectionID int) int {
+	if len(holder) != 20 {
+		panic("bad owner address")
+	}
+	ctx := storage.GetReadOnlyContext()
+	key := append(mkAccountPrefix(holder), []byte(std.Itoa10(collectionID)+tokenIDSeparator)...)
+	iter := storage.Find(ctx, key, storage.KeysOnly)
+	count := 0
+	for iterator.Next(iter) {
+		count++
+	}
+	return count
+}
+
 // TokensOf returns an iterator with all tokens held by the specified address.
 func TokensOf(holder interop.Hash160) iterator.Iterator {
 	if len(holder) != 20 {
@@ -162,10 +248,12 @@ func Transfer(to interop.Hash160, token []byte, data any) bool {
 	nft := getNFT(ctx, token)
 	from := nft.Owner
 
-	if !runtime.CheckWitness(from) {
+	if !isTransferAllowed(ctx, from, token) {
 		return false
 	}
 
+	storage.Delete(ctx, mkApprovalKey(token))
+
 	if !from.Equals(to) {
 		nft.Owner = to
 		setNFT(ctx, token, nft)
@@ -180,6 +268,86 @@ func Transfer(to interop.Hash160, token []byte, data any) bool {
 	return true
 }
 
+// Approve allows the account (usually a contract, e.g. auction or marketplace) to
+// transfer the token on the owner's behalf. Approval is reset on every transfer,
+// nil account removes it.
+func Approve(approved interop.Hash160, token []byte) bool {
+	if approved != nil && len(approved) != 20 {
+		panic("invalid 'approved' address")
+	}
+	ctx := storage.GetContext()
+	owner := getNFT(ctx, token).Owner
+
+	if !runtime.CheckWitness(owner) {
+		return false
+	}
+
+	if approved == nil {
+		storage.Delete(ctx, mkApprovalKey(token))
+	} else {
+		storage.Put(ctx, mkApprovalKey(token), approved)
+	}
+
+	runtime.Notify("Approval", owner, approved, token)
+	return true
+}
+
+// SetApprovalForAll allows or forbids the operator to transfer any token of the owner.
+func SetApprovalForAll(owner interop.Hash160, operator interop.Hash160, approved bool) bool {
+	if len(owner) != 20 || len(operator) != 20 {
+		panic("invalid address")
+	}
+	if !runtime.CheckWitness(owner) {
+		return false
+	}
+
+	ctx := storage.GetContext()
+	if approved {
+		storage.Put(ctx, mkOperatorKey(owner, operator), true)
+	} else {
+		storage.Delete(ctx, mkOperatorKey(owner, operator))
+	}
+
+	runtime.Notify("ApprovalForAll", owner, operator, approved)
+	return true
+}
+
+// GetApproved returns the account allowed to transfer the token or nil.
+func GetApproved(token []byte) interop.Hash160 {
+	ctx := storage.GetReadOnlyContext()
+	getNFT(ctx, token)
+	val := storage.Get(ctx, mkApprovalKey(token))
+	if val == nil {
+		return nil
+	}
+	return val.(interop.Hash160)
+}
+
+// IsApprovedForAll checks whether the operator may transfer all tokens of the owner.
+func IsApprovedForAll(owner interop.Hash160, operator interop.Hash160) bool {
+	return storage.Get(storage.GetReadOnlyContext(), mkOperatorKey(owner, operator)) != nil
+}
+
+// isTransferAllowed checks that the transfer is witnessed by the token owner or
+// is made by an approved account: either calling contract or transaction signer.
+func isTransferAllowed(ctx storage.Context, owner interop.Hash160, token []byte) bool {
+	if runtime.CheckWitness(owner) {
+		return true
+	}
+
+	caller := runtime.GetCallingScriptHash()
+
+	approved := storage.Get(ctx, mkApprovalKey(token))
+	if approved != nil {
+		approvedHash := approved.(interop.Hash160)
+		if caller.Equals(approvedHash) || runtime.CheckWitness(approvedHash) {
+			return true
+		}
+	}
+
+	return storage.Get(ctx, mkOperatorKey(owner, caller)) != nil
+}
+
 func getNFT(ctx storage.Context, token []byte) NFTItem {
 	key := mkTokenKey(token)
 	val := storage.Get(ctx, key)
@@ -187,9 +355,19 @@ func getNFT(ctx storage.Context, token []byte) NFTItem {
 		panic("no token found")
 	}
 
-	serializedNFT := val.([]byte)
-	deserializedNFT := std.Deserialize(serializedNFT)
-	return deserializedNFT.(NFTItem)
+	return decodeNFT(val.([]byte))
+}
+
+// decodeNFT deserializes stored NFTItem. Tokens minted by the first version of
+// the contract have only ID, Name, Owner and Address, they get zero values of
+// the other fields.
+func decodeNFT(data []byte) NFTItem {
+	item := std.Deserialize(data)
+	f := item.([]any)
+	if len(f) == 4 {
+		item = []any{f[0], f[1], f[2], f[3], []byte{}, 0, 0, "", "", "", "", "", "", "", 0}
+	}
+	return item.(NFTItem)
 }
 
 func nftExists(ctx storage.Context, token []byte) bool {
@@ -211,21 +389,124 @@ func postTransfer(from interop.Hash160, to interop.Hash160, token []byte, data a
 	}
 }
 
-func Mint(user interop.Hash160, name string) []byte { // пользователь, которму выписываем токен и имя токена=название файла с гифкой
+// CreateCollection registers a new ticket series with the given supply cap and
+// returns its ID. Only the contract owner can create collections.
+func CreateCollection(name string, description string, source string, maxSupply int) int {
 	ctx := storage.GetContext()
-	tokenID := crypto.Sha256([]byte(name))
+	checkOwnerWitness(ctx)
+
+	if len(name) == 0 {
+		panic("empty collection name")
+	}
+	if maxSupply <= 0 {
+		panic("max supply must be positive")
+	}
+
+	id := 1
+	last := storage.Get(ctx, lastCollectionKey)
+	if last != nil {
+		id = last.(int) + 1
+	}
+	storage.Put(ctx, lastCollectionKey, id)
+
+	setCollection(ctx, Collection{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Source:      source,
+		MaxSupply:   maxSupply,
+	})
+
+	runtime.Notify("CollectionCreated", id, name, maxSupply)
+
+	return id
+}
+
+// GetCollection returns the collection with the given ID.
+func GetCollection(id int) Collection {
+	return getCollection(storage.GetReadOnlyContext(), id)
+}
+
+// TokensOfCollection returns an iterator with all tokens minted in the collection.
+func TokensOfCollection(id int) iterator.Iterator {
+	ctx := storage.GetReadOnlyContext()
+	getCollection(ctx, id)
+	key := append([]byte(collectionTokensPrefix), []byte(std.Itoa10(id)+tokenIDSeparator)...)
+	return storage.Find(ctx, key, storage.ValuesOnly)
+}
+
+// CancelCollection marks the collection as cancelled, all its tickets become
+// void: they can't be minted, sold on market or auctioned anymore, but their
+// holders can claim refunds in auction contract.
+func CancelCollection(collectionID int) {
+	ctx := storage.GetContext()
+	checkOwnerWitness(ctx)
+
+	col := getCollection(ctx, collectionID)
+	if col.Cancelled {
+		panic("collection is already cancelled")
+	}
+	col.Cancelled = true
+	setCollection(ctx, col)
+
+	runtime.Notify("CollectionCancelled", collectionID)
+}
+
+// IsCancelled returns true if the collection is cancelled.
+func IsCancelled(collectionID int) bool {
+	return getCollection(storage.GetReadOnlyContext(), collectionID).Cancelled
+}
+
+// IsVoid returns true if the token belongs to a cancelled collection.
+func IsVoid(token []byte) bool {
+	ctx := storage.GetReadOnlyContext()
+	nft := getNFT(ctx, token)
+	if nft.Collection == 0 {
+		return false
+	}
+	return getCollection(ctx, nft.Collection).Cancelled
+}
+
+// CollectionOf returns ID of the token collection, 0 if the token has none.
+func CollectionOf(token []byte) int {
+	return getNFT(storage.GetReadOnlyContext(), token).Collection
+}
+
+// Mint creates the next ticket of the collection from the data staged by
+// StageTicket, so a token never exists without its FrostFS address and metadata.
+func Mint(user interop.Hash160, collectionID int) []byte { // пользователь, которму выписываем токен, и серия билетов
+	ctx := storage.GetContext()
+	col := getCollection(ctx, collectionID)
+	if col.Cancelled {
+		panic("collection is cancelled")
+	}
+	if col.Minted >= col.MaxSupply {
+		panic("collection is sold out")
+	}
+
+	serial := col.Minted + 1
+	tokenID := mkTokenID(collectionID, serial)
 	if nftExists(ctx, tokenID) {
 		panic("token already exists")
 	}
 
-	nft := NFTItem{
-		ID:    tokenID,
-		Owner: user,
-		Name:  name,
+	stageKey := mkStageKey(tokenID)
+	staged := storage.Get(ctx, stageKey)
+	if staged == nil {
+		panic("ticket data is not staged")
 	}
+	storage.Delete(ctx, stageKey)
+
+	nft := std.Deserialize(staged.([]byte)).(NFTItem)
+	nft.ID = tokenID
+	nft.Owner = user
 	setNFT(ctx, tokenID, nft)
 	addToBalance(ctx, user, 1)
 	addToken(ctx, user, tokenID)
+	storage.Put(ctx, append([]byte(collectionTokensPrefix), tokenID...), tokenID)
+
+	col.Minted = serial
+	setCollection(ctx, col)
 
 	total := storage.Get(ctx, totalSupplyKey).(int) + 1
 	storage.Put(ctx, totalSupplyKey, total)
@@ -233,16 +514,118 @@ func Mint(user interop.Hash160, name string) []byte { // пользовател
 	return tokenID
 }
 
-func SetAddress(name string, address string) {
+// StageTicket prepares FrostFS address, content hash and metadata of the ticket
+// with the given serial. It's called by the backend before it signs the mint request.
+func StageTicket(collectionID int, serial int, address string, hash []byte, description string, image string, eventName string, date string, row string, seat string, category string, faceValue int) {
+	ctx := storage.GetContext()
+	checkOwnerWitness(ctx)
+
+	if len(hash) != 32 {
+		panic("invalid content hash length")
+	}
+	if faceValue < 0 {
+		panic("face value must not be negative")
+	}
+	col := getCollection(ctx, collectionID)
+	if serial <= col.Minted || serial > col.MaxSupply {
+		panic("invalid serial")
+	}
+
+	nft := NFTItem{
+		Name:        col.Name + " #" + std.Itoa10(serial),
+		Address:     address,
+		Hash:        hash,
+		Collection:  collectionID,
+		Serial:      serial,
+		Description: description,
+		Image:       image,
+		EventName:   eventName,
+		Date:        date,
+		Row:         row,
+		Seat:        seat,
+		Category:    category,
+		FaceValue:   faceValue,
+	}
+	storage.Put(ctx, mkStageKey(mkTokenID(collectionID, serial)), std.Serialize(nft))
+}
+
+// GetFaceValue returns the original price of the ticket, 0 if it's unknown.
+func GetFaceValue(token []byte) int {
+	return getNFT(storage.GetReadOnlyContext(), token).FaceValue
+}
+
+// SetRoyalty sets the account receiving royalty from resales of the collection
+// tickets and its rate in basis points. Zero rate disables royalties.
+func SetRoyalty(collectionID int, recipient interop.Hash160, rate int) {
 	ctx := storage.GetContext()
+	checkOwnerWitness(ctx)
+
+	if len(recipient) != 20 {
+		panic("invalid royalty recipient")
+	}
+	if rate < 0 || rate > maxRoyaltyRate {
+		panic("invalid royalty rate")
+	}
+
+	col := getCollection(ctx, collectionID)
+	col.RoyaltyRecipient = recipient
+	col.RoyaltyRate = rate
+	setCollection(ctx, col)
+}
+
+// RoyaltyInfo returns royalty recipients and amounts for the sale of the token
+// at the given price (NEP-24). Royalty is the same for any payment token.
+func RoyaltyInfo(token []byte, royaltyToken interop.Hash160, salePrice int) []RoyaltyRecipient {
+	if salePrice < 0 {
+		panic("invalid sale price")
+	}
+	ctx := storage.GetReadOnlyContext()
+	nft := getNFT(ctx, token)
+
+	res := []RoyaltyRecipient{}
+	if nft.Collection == 0 {
+		return res
+	}
+
+	col := getCollection(ctx, nft.Collection)
+	if col.RoyaltyRate == 0 || col.RoyaltyRecipient == nil {
+		return res
+	}
+
+	return append(res, RoyaltyRecipient{
+		Address: col.RoyaltyRecipient,
+		Amount:  salePrice * col.RoyaltyRate / maxRoyaltyRate,
+	})
+}
+
+func checkOwnerWitness(ctx storage.Context) {
 	if !runtime.CheckWitness(storage.Get(ctx, ownerKey).(interop.Hash160)) {
 		panic("not witnessed")
 	}
+}
 
-	tokenID := crypto.Sha256([]byte(name))
-	nft := getNFT(ctx, tokenID)
-	nft.Address = address
-	setNFT(ctx, tokenID, nft)
+func getCollection(ctx storage.Context, id int) Collection {
+	val := storage.Get(ctx, mkCollectionKey(id))
+	if val == nil {
+		panic("no collection found")
+	}
+	return std.Deserialize(val.([]byte)).(Collection)
+}
+
+func setCollection(ctx storage.Context, col Collection) {
+	storage.Put(ctx, mkCollectionKey(col.ID), std.Serialize(col))
+}
+
+// mkTokenID creates token ID from the collection ID and the serial number
+// of the ticket in it.
+func mkTokenID(collectionID int, serial int) []byte {
+	return []byte(std.Itoa10(collectionID) + tokenIDSeparator + std.Itoa10(serial))
+}
+
+// mkCollectionKey creates DB key for the collection specified by concatenating
+// collectionPrefix and collection ID.
+func mkCollectionKey(id int) []byte {
+	return []byte(collectionPrefix + std.Itoa10(id))
 }
 
 // mkAccountPrefix creates DB key-prefix for the account tokens specified
@@ -259,6 +642,28 @@ func mkBalanceKey(holder interop.Hash160) []byte {
 	return append(res, holder...)
 }
 
+// mkStageKey creates DB key for the staged ticket data by concatenating stagePrefix
+// and token ID.
+func mkStageKey(tokenID []byte) []byte {
+	res := []byte(stagePrefix)
+	return append(res, tokenID...)
+}
+
+// mkApprovalKey creates DB key for the token approval by concatenating approvalPrefix
+// and token ID.
+func mkApprovalKey(tokenID []byte) []byte {
+	res := []byte(approvalPrefix)
+	return append(res, tokenID...)
+}
+
+// mkOperatorKey creates DB key for the operator approval by concatenating
+// operatorPrefix, owner and operator addresses.
+func mkOperatorKey(owner interop.Hash160, operator interop.Hash160) []byte {
+	res := []byte(operatorPrefix)
+	res = append(res, owner...)
+	return append(res, operator...)
+}
+
 // mkTokenKey creates DB key for the token specified by concatenating tokenPrefix
 // and token ID.
 func mkTokenKey(tokenID []byte) []byte {